@@ -0,0 +1,178 @@
+package main
+
+import (
+    "crypto/subtle"
+    "errors"
+    "net/http"
+    "net/url"
+    "sort"
+    "strings"
+
+    "github.com/go-chi/chi/v5"
+    "github.com/jackc/pgx/v5"
+)
+
+// requireAPIKey guards admin routes with the X-API-Key header. An empty key
+// disables the routes entirely rather than leaving them open.
+func requireAPIKey(key string) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            if key == "" {
+                respondError(w, http.StatusForbidden, "admin endpoints are disabled")
+                return
+            }
+            given := r.Header.Get("X-API-Key")
+            if subtle.ConstantTimeCompare([]byte(given), []byte(key)) != 1 {
+                respondError(w, http.StatusUnauthorized, "invalid api key")
+                return
+            }
+            next.ServeHTTP(w, r)
+        })
+    }
+}
+
+// confidenceBucket is the canonical bucket for a confidence score. It must
+// stay in step with calculate_confidence_bucket in database/schema.sql and
+// with confidenceBucketExpr.
+func confidenceBucket(score int) string {
+    switch {
+    case score >= 60:
+        return "high"
+    case score >= 50:
+        return "medium"
+    default:
+        return "low"
+    }
+}
+
+// confidenceBucketExpr is confidenceBucket in SQL, for a predictions row.
+const confidenceBucketExpr = "(CASE WHEN p.confidence_score >= 60 THEN 'high' WHEN p.confidence_score >= 50 THEN 'medium' ELSE 'low' END)"
+
+// isConfidenceBucket reports whether name is one of confidenceBucket's
+// outputs. name must already be lower case.
+func isConfidenceBucket(name string) bool {
+    return name == "high" || name == "medium" || name == "low"
+}
+
+type bucketMismatch struct {
+    Stored   string `json:"stored"`
+    Expected string `json:"expected"`
+    Count    int    `json:"count"`
+}
+
+type bucketAuditResponse struct {
+    Checked    int              `json:"checked"`
+    Mismatched int              `json:"mismatched"`
+    Unbucketed int              `json:"unbucketed"`
+    Mismatches []bucketMismatch `json:"mismatches"`
+}
+
+// handleBucketAudit counts predictions whose stored confidence_bucket
+// disagrees with confidenceBucket(confidence_score). Rows with no bucket yet
+// (the trigger only fills it on resolution) are reported separately.
+func (s *server) handleBucketAudit(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+
+    rows, err := s.db.Query(ctx, `SELECT confidence_score, confidence_bucket, COUNT(*)
+        FROM predictions
+        GROUP BY confidence_score, confidence_bucket`)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    resp := bucketAuditResponse{Mismatches: []bucketMismatch{}}
+    byPair := map[[2]string]int{}
+    for rows.Next() {
+        var score, count int
+        var stored *string
+        if err := rows.Scan(&score, &stored, &count); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        resp.Checked += count
+        if stored == nil || *stored == "" {
+            resp.Unbucketed += count
+            continue
+        }
+        expected := confidenceBucket(score)
+        if !strings.EqualFold(*stored, expected) {
+            resp.Mismatched += count
+            byPair[[2]string{*stored, expected}] += count
+        }
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    for pair, count := range byPair {
+        resp.Mismatches = append(resp.Mismatches, bucketMismatch{Stored: pair[0], Expected: pair[1], Count: count})
+    }
+    sort.Slice(resp.Mismatches, func(i, j int) bool {
+        return resp.Mismatches[i].Count > resp.Mismatches[j].Count
+    })
+
+    respondJSON(w, resp)
+}
+
+type resolveResponse struct {
+    MatchID      string `json:"match_id"`
+    ActualWinner string `json:"actual_winner"`
+    Updated      int64  `json:"updated"`
+}
+
+// handleResolveMatch copies the live feed's winner onto the match's
+// predictions that don't have one yet and grades them. Rows that are
+// already resolved are left alone, so re-running it is a no-op.
+func (s *server) handleResolveMatch(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+    matchID, err := url.PathUnescape(chi.URLParam(r, "matchId"))
+    if err != nil || matchID == "" {
+        respondError(w, http.StatusBadRequest, "invalid match id")
+        return
+    }
+
+    tx, err := s.db.Begin(ctx)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback(ctx)
+
+    // FOR SHARE keeps the live row from changing under the update.
+    var winner *string
+    err = tx.QueryRow(ctx, `SELECT NULLIF(actual_winner, '')
+        FROM live_matches
+        WHERE match_identifier = $1
+        FOR SHARE`, matchID).Scan(&winner)
+    if errors.Is(err, pgx.ErrNoRows) {
+        respondError(w, http.StatusNotFound, "no live data for match")
+        return
+    }
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    if winner == nil {
+        respondError(w, http.StatusConflict, "live match has no winner yet")
+        return
+    }
+
+    tag, err := tx.Exec(ctx, `UPDATE predictions
+        SET actual_winner = $2,
+            prediction_correct = (predicted_winner = $2),
+            confidence_bucket = calculate_confidence_bucket(confidence_score)
+        WHERE match_id = $1 AND NULLIF(actual_winner, '') IS NULL`, matchID, *winner)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    if err := tx.Commit(ctx); err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, resolveResponse{MatchID: matchID, ActualWinner: *winner, Updated: tag.RowsAffected()})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// bucketRule is one ">= threshold then bucket" branch of a SQL bucketing
+// expression; rules are tried in order and the fallback applies below all.
+type bucketRule struct {
+    threshold int
+    bucket    string
+}
+
+// evalBucketRules applies rules the way the SQL CASE / IF chain does.
+func evalBucketRules(rules []bucketRule, fallback string, score int) string {
+    for _, rule := range rules {
+        if score >= rule.threshold {
+            return rule.bucket
+        }
+    }
+    return fallback
+}
+
+var (
+    caseRuleRe     = regexp.MustCompile(`WHEN p\.confidence_score >= (\d+) THEN '(\w+)'`)
+    caseFallbackRe = regexp.MustCompile(`ELSE '(\w+)' END`)
+    plpgsqlRuleRe  = regexp.MustCompile(`(?:IF|ELSIF) confidence >= (\d+) THEN\s+RETURN '(\w+)';`)
+    plpgsqlElseRe  = regexp.MustCompile(`ELSE\s+RETURN '(\w+)';`)
+)
+
+func parseBucketRules(t *testing.T, src string, ruleRe, fallbackRe *regexp.Regexp) ([]bucketRule, string) {
+    t.Helper()
+    var rules []bucketRule
+    for _, m := range ruleRe.FindAllStringSubmatch(src, -1) {
+        threshold, err := strconv.Atoi(m[1])
+        if err != nil {
+            t.Fatalf("threshold %q: %v", m[1], err)
+        }
+        rules = append(rules, bucketRule{threshold, m[2]})
+    }
+    fallback := fallbackRe.FindStringSubmatch(src)
+    if len(rules) == 0 || fallback == nil {
+        t.Fatalf("could not parse bucket rules from %q", src)
+    }
+    return rules, fallback[1]
+}
+
+// schemaBucketFunction returns the body of calculate_confidence_bucket from
+// database/schema.sql.
+func schemaBucketFunction(t *testing.T) string {
+    t.Helper()
+    schema, err := os.ReadFile("../../database/schema.sql")
+    if err != nil {
+        t.Skipf("schema not available: %v", err)
+    }
+    src := string(schema)
+    start := strings.Index(src, "FUNCTION calculate_confidence_bucket")
+    if start < 0 {
+        t.Fatal("calculate_confidence_bucket not found in schema.sql")
+    }
+    end := strings.Index(src[start:], "$$ LANGUAGE")
+    if end < 0 {
+        t.Fatal("end of calculate_confidence_bucket not found")
+    }
+    return src[start : start+end]
+}
+
+func TestConfidenceBucketEdges(t *testing.T) {
+    tests := []struct {
+        score int
+        want  string
+    }{
+        {0, "low"},
+        {49, "low"},
+        {50, "medium"},
+        {59, "medium"},
+        {60, "high"},
+        {100, "high"},
+    }
+    exprRules, exprFallback := parseBucketRules(t, confidenceBucketExpr, caseRuleRe, caseFallbackRe)
+    sqlRules, sqlFallback := parseBucketRules(t, schemaBucketFunction(t), plpgsqlRuleRe, plpgsqlElseRe)
+
+    for _, tt := range tests {
+        if got := confidenceBucket(tt.score); got != tt.want {
+            t.Errorf("confidenceBucket(%d) = %q, want %q", tt.score, got, tt.want)
+        }
+        if got := evalBucketRules(exprRules, exprFallback, tt.score); got != tt.want {
+            t.Errorf("confidenceBucketExpr at %d = %q, want %q", tt.score, got, tt.want)
+        }
+        if got := evalBucketRules(sqlRules, sqlFallback, tt.score); got != tt.want {
+            t.Errorf("calculate_confidence_bucket(%d) = %q, want %q", tt.score, got, tt.want)
+        }
+        if !isConfidenceBucket(tt.want) {
+            t.Errorf("isConfidenceBucket(%q) = false", tt.want)
+        }
+    }
+}
+
+// Every threshold in the SQL must be a bucket edge in Go too, so adding a
+// bucket on one side only fails here rather than in the audit.
+func TestConfidenceBucketThresholdsMatchSQL(t *testing.T) {
+    exprRules, _ := parseBucketRules(t, confidenceBucketExpr, caseRuleRe, caseFallbackRe)
+    sqlRules, _ := parseBucketRules(t, schemaBucketFunction(t), plpgsqlRuleRe, plpgsqlElseRe)
+    if len(exprRules) != len(sqlRules) {
+        t.Fatalf("confidenceBucketExpr has %d thresholds, schema.sql has %d", len(exprRules), len(sqlRules))
+    }
+    for i, rule := range sqlRules {
+        if exprRules[i] != rule {
+            t.Errorf("threshold %d: confidenceBucketExpr %+v, schema.sql %+v", i, exprRules[i], rule)
+        }
+        if got := confidenceBucket(rule.threshold); got != rule.bucket {
+            t.Errorf("confidenceBucket(%d) = %q, want %q", rule.threshold, got, rule.bucket)
+        }
+        if got := confidenceBucket(rule.threshold - 1); got == rule.bucket {
+            t.Errorf("confidenceBucket(%d) = %q, want the bucket below %q", rule.threshold-1, got, rule.bucket)
+        }
+    }
+}
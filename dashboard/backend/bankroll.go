@@ -0,0 +1,381 @@
+package main
+
+import (
+    "fmt"
+    "math"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// maxKellyStake caps the share of bankroll a single Kelly bet may take, so
+// an overconfident score can't stake most of the bankroll on one match.
+const maxKellyStake = 0.25
+
+// kellyStake is the fraction of bankroll to stake on a bet won with
+// probability p at decimal odds, scaled by fraction (1 is full Kelly) and
+// capped at maxKellyStake. Bets with no edge stake nothing.
+func kellyStake(p, odds, fraction float64) float64 {
+    b := odds - 1
+    if b <= 0 {
+        return 0
+    }
+    f := (b*p - (1 - p)) / b * fraction
+    return math.Max(0, math.Min(f, maxKellyStake))
+}
+
+type bankrollPoint struct {
+    PredictionID int        `json:"prediction_id"`
+    Day          *time.Time `json:"day"`
+    Stake        float64    `json:"stake"`
+    Profit       float64    `json:"profit"`
+    Bankroll     float64    `json:"bankroll"`
+}
+
+type bankrollResponse struct {
+    Strategy string          `json:"strategy"`
+    Initial  float64         `json:"initial"`
+    Final    float64         `json:"final"`
+    Bets     int             `json:"bets"`
+    Busted   bool            `json:"busted"`
+    Curve    []bankrollPoint `json:"curve"`
+}
+
+// handleStatsBankroll replays the filtered, resolved value bets in date
+// order from an initial bankroll (default 1000). strategy=flat stakes a
+// fixed amount (stake, default 10); strategy=kelly stakes a Kelly fraction
+// (kellyFraction, default 0.5) of the current bankroll, using confidence as
+// the win probability. Bets without valid odds are left out, as a win at
+// odds of 1 or less can't pay. The walk stops if the bankroll runs out.
+func (s *server) handleStatsBankroll(w http.ResponseWriter, r *http.Request) {
+    strategy := strings.ToLower(strings.TrimSpace(paramStrategy.get(r)))
+    if strategy == "" {
+        strategy = "flat"
+    }
+    if strategy != "flat" && strategy != "kelly" {
+        respondError(w, http.StatusBadRequest, "strategy must be flat or kelly")
+        return
+    }
+    initial, ok := parsePositiveFloat(paramInitial.get(r), 1000)
+    if !ok {
+        respondError(w, http.StatusBadRequest, "initial must be a positive number")
+        return
+    }
+    flatStake, ok := parsePositiveFloat(paramStake.get(r), 10)
+    if !ok {
+        respondError(w, http.StatusBadRequest, "stake must be a positive number")
+        return
+    }
+    fraction, ok := parsePositiveFloat(paramKellyFraction.get(r), 0.5)
+    if !ok || fraction > 1 {
+        respondError(w, http.StatusBadRequest, "kellyFraction must be in (0, 1]")
+        return
+    }
+
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    query, args := buildBankrollQuery(filters)
+    rows, err := s.replica.Query(r.Context(), query, args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    sim := newBankrollSim(strategy, initial, flatStake, fraction)
+    for rows.Next() {
+        var bet bankrollBet
+        if err := rows.Scan(&bet.PredictionID, &bet.Day, &bet.Confidence, &bet.Odds, &bet.Correct); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        sim.place(bet)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, sim.result())
+}
+
+// buildBankrollQuery selects the resolved value bets with valid odds in the
+// order handleStatsBankroll replays them.
+func buildBankrollQuery(filters filterSet) (string, []any) {
+    filters.ResolvedOnly = true
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, "p.value_bet", "NOT "+invalidOddsExpr)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        p.prediction_id,
+        p.prediction_day,
+        p.confidence_score,
+        (` + predictedOddsExpr + `)::float8,
+        ` + correctExpr + `
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" ORDER BY p.prediction_day, p.created_at, p.prediction_id")
+    return base.String(), args
+}
+
+// bankrollBet is one resolved bet as the simulation sees it.
+type bankrollBet struct {
+    PredictionID int
+    Day          *time.Time
+    Confidence   int
+    Odds         float64
+    Correct      bool
+}
+
+// bankrollSim walks bets in order, sizing each stake by strategy.
+type bankrollSim struct {
+    strategy  string
+    flatStake float64
+    fraction  float64
+    bankroll  float64
+    resp      bankrollResponse
+}
+
+func newBankrollSim(strategy string, initial, flatStake, fraction float64) *bankrollSim {
+    return &bankrollSim{
+        strategy:  strategy,
+        flatStake: flatStake,
+        fraction:  fraction,
+        bankroll:  initial,
+        resp:      bankrollResponse{Strategy: strategy, Initial: initial, Curve: []bankrollPoint{}},
+    }
+}
+
+// place stakes on bet and records the result. Bets with odds of 1 or less,
+// which the query already excludes, and every bet after a bust are skipped.
+func (b *bankrollSim) place(bet bankrollBet) {
+    if b.resp.Busted || !(bet.Odds > 1) {
+        return
+    }
+    stake := b.flatStake
+    if b.strategy == "kelly" {
+        stake = b.bankroll * kellyStake(float64(bet.Confidence)/100, bet.Odds, b.fraction)
+    }
+    stake = math.Min(stake, b.bankroll)
+    if stake <= 0 {
+        return
+    }
+    profit := -stake
+    if bet.Correct {
+        profit = stake * (bet.Odds - 1)
+    }
+    b.bankroll += profit
+    b.resp.Bets++
+    b.resp.Curve = append(b.resp.Curve, bankrollPoint{
+        PredictionID: bet.PredictionID,
+        Day:          bet.Day,
+        Stake:        roundTo(stake, 2),
+        Profit:       roundTo(profit, 2),
+        Bankroll:     roundTo(b.bankroll, 2),
+    })
+    if b.bankroll <= 0 {
+        b.resp.Busted = true
+    }
+}
+
+func (b *bankrollSim) result() bankrollResponse {
+    resp := b.resp
+    resp.Final = roundTo(b.bankroll, 2)
+    return resp
+}
+
+type valueBetRecordResponse struct {
+    Won     int      `json:"won"`
+    Lost    int      `json:"lost"`
+    WinRate *float64 `json:"win_rate"`
+    Stake   float64  `json:"stake"`
+    Bets    int      `json:"bets"`
+    Staked  float64  `json:"staked"`
+    Profit  float64  `json:"profit"`
+    ROI     *float64 `json:"roi"`
+}
+
+// handleStatsValueBetRecord is the landing page's headline: won and lost
+// counts for resolved value bets and the profit and ROI of a flat stake
+// (stake, default 10) on each at the predicted winner's odds. The usual
+// filters, including the date window, apply.
+//
+// won, lost and win_rate count every resolved value bet. bets, staked,
+// profit and roi only count those with valid odds, since a win at odds of
+// 1 or less can't pay and would understate the return.
+func (s *server) handleStatsValueBetRecord(w http.ResponseWriter, r *http.Request) {
+    stake, ok := parsePositiveFloat(paramStake.get(r), 10)
+    if !ok {
+        respondError(w, http.StatusBadRequest, "stake must be a positive number")
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    valueBet := true
+    filters.ValueBet = &valueBet
+    clauses, args := buildWhereClauses(filters)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        COUNT(*) FILTER (WHERE ` + correctExpr + `),
+        COUNT(*) FILTER (WHERE NOT ` + correctExpr + `),
+        ` + unitStakeColumns() + `
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+
+    resp := valueBetRecordResponse{Stake: stake}
+    var priced unitStakeReturn
+    if err := s.replica.QueryRow(r.Context(), base.String(), args...).Scan(
+        &resp.Won, &resp.Lost, &priced.Won, &priced.Lost, &priced.Winnings); err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    if bets := resp.Won + resp.Lost; bets > 0 {
+        rate := accuracy(resp.Won, bets)
+        resp.WinRate = &rate
+    }
+    resp.Bets = priced.bets()
+    resp.Staked = roundTo(stake*float64(resp.Bets), oddsDecimals)
+    resp.Profit = roundTo(stake*priced.profit(), oddsDecimals)
+    resp.ROI = priced.roi()
+
+    respondJSON(w, resp)
+}
+
+// unitStakeReturn is the outcome of a unit stake on each of a set of bets
+// at the predicted winner's odds. Winnings is the sum of odds-1 over the
+// won bets.
+type unitStakeReturn struct {
+    Won      int
+    Lost     int
+    Winnings float64
+}
+
+func (u unitStakeReturn) bets() int { return u.Won + u.Lost }
+
+// profit is the net return in units.
+func (u unitStakeReturn) profit() float64 { return u.Winnings - float64(u.Lost) }
+
+// roi is profit per unit staked, nil when there were no bets.
+func (u unitStakeReturn) roi() *float64 {
+    if u.bets() == 0 {
+        return nil
+    }
+    roi := roundTo(u.profit()/float64(u.bets()), ratioDecimals)
+    return &roi
+}
+
+// unitStakeColumns selects the won count, lost count and winnings of a
+// unitStakeReturn over the resolved rows with valid odds. Rows with invalid
+// odds are left out, as a win there can't pay.
+func unitStakeColumns() string {
+    priced := actualWinnerExpr + " IS NOT NULL AND NOT " + invalidOddsExpr
+    return `COUNT(*) FILTER (WHERE ` + priced + ` AND ` + correctExpr + `),
+        COUNT(*) FILTER (WHERE ` + priced + ` AND NOT ` + correctExpr + `),
+        COALESCE(SUM(` + predictedOddsExpr + ` - 1) FILTER (WHERE ` + priced + ` AND ` + correctExpr + `), 0)::float8`
+}
+
+type stakingPlanResponse struct {
+    Bankroll float64        `json:"bankroll"`
+    Data     predictionRows `json:"data"`
+}
+
+// stakingPlanLimit and maxStakingPlanLimit are the default and largest
+// number of bets a staking plan lists.
+const (
+    stakingPlanLimit    = 50
+    maxStakingPlanLimit = 200
+)
+
+// kellyStakeExpr is kellyStake in SQL for a predictions row with valid
+// odds, the fraction bound to placeholder n. It orders the staking plan in
+// the database so the limit keeps the largest stakes.
+func kellyStakeExpr(n int) string {
+    b := "((" + predictedOddsExpr + ") - 1)"
+    p := "(p.confidence_score / 100.0)"
+    return fmt.Sprintf("GREATEST(0, LEAST(%g, (%s * %s - (1 - %s)) / %s * $%d::float8))", maxKellyStake, b, p, p, b, n)
+}
+
+// handleStakingPlan lists the upcoming value bets, those unresolved and
+// dated today or later in the user's timezone, with the Kelly fraction of
+// bankroll (default 1000) to stake on each, largest stake first, for
+// allocating a bankroll top-down. kellyFraction (default 0.5) scales full
+// Kelly and each stake is capped at maxKellyStake. limit (default 50, at
+// most 200) caps the list. Rows with invalid odds are left out; bets with
+// no edge sort last with a zero stake.
+func (s *server) handleStakingPlan(w http.ResponseWriter, r *http.Request) {
+    bankroll, ok := parsePositiveFloat(paramBankroll.get(r), 1000)
+    if !ok {
+        respondError(w, http.StatusBadRequest, "bankroll must be a positive number")
+        return
+    }
+    fraction, ok := parsePositiveFloat(paramKellyFraction.get(r), 0.5)
+    if !ok || fraction > 1 {
+        respondError(w, http.StatusBadRequest, "kellyFraction must be in (0, 1]")
+        return
+    }
+    limit, err := parseIntInRange(r, paramLimit, stakingPlanLimit, 1, maxStakingPlanLimit)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    valueBet := true
+    filters.ValueBet = &valueBet
+    clauses, args := buildWhereClauses(filters)
+    args = append(args, filters.Timezone)
+    clauses = append(clauses,
+        actualWinnerExpr+" IS NULL",
+        "NOT "+invalidOddsExpr,
+        fmt.Sprintf("p.prediction_day >= (NOW() AT TIME ZONE $%d)::date", len(args)))
+
+    base := strings.Builder{}
+    writePredictionSelect(&base, false)
+    writeWhere(&base, clauses)
+    args = append(args, fraction)
+    base.WriteString(" ORDER BY " + kellyStakeExpr(len(args)) + " DESC, p.prediction_day, p.prediction_id")
+    args = append(args, limit)
+    base.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+
+    bets, err := s.fetchPredictions(r.Context(), base.String(), args)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    for i := range bets {
+        f := kellyStake(float64(bets[i].ConfidenceScore)/100, bets[i].predictedOdds(), fraction)
+        stake := roundTo(bankroll*f, 2)
+        f = roundTo(f, ratioDecimals)
+        bets[i].KellyFraction = &f
+        bets[i].RecommendedStake = &stake
+    }
+
+    respondJSON(w, stakingPlanResponse{Bankroll: bankroll, Data: newPredictionRows(r, bets)})
+}
+
+// parsePositiveFloat parses v as a positive finite number, returning
+// fallback when v is empty.
+func parsePositiveFloat(v string, fallback float64) (float64, bool) {
+    v = strings.TrimSpace(v)
+    if v == "" {
+        return fallback, true
+    }
+    f, err := strconv.ParseFloat(v, 64)
+    if err != nil || f <= 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+        return 0, false
+    }
+    return f, true
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+    "fmt"
+    "math"
+    "strings"
+    "testing"
+)
+
+func TestKellyStake(t *testing.T) {
+    tests := []struct {
+        name     string
+        p        float64
+        odds     float64
+        fraction float64
+        want     float64
+    }{
+        {"fair bet has no edge", 0.5, 2, 1, 0},
+        {"negative edge stakes nothing", 0.4, 2, 1, 0},
+        {"full kelly", 0.6, 2, 1, 0.2},
+        {"half kelly", 0.6, 2, 0.5, 0.1},
+        {"quarter kelly", 0.6, 2, 0.25, 0.05},
+        {"zero fraction", 0.6, 2, 0, 0},
+        {"capped", 0.9, 3, 1, maxKellyStake},
+        {"cap applies after fraction", 0.9, 3, 0.5, maxKellyStake},
+        {"fraction brings it under the cap", 0.9, 3, 0.25, 0.2125},
+        {"certain win is capped", 1, 1.5, 1, maxKellyStake},
+        {"odds of 1", 0.9, 1, 1, 0},
+        {"odds below 1", 0.9, 0.5, 1, 0},
+        {"zero odds", 0.9, 0, 1, 0},
+        {"negative odds", 0.9, -2, 1, 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := kellyStake(tt.p, tt.odds, tt.fraction)
+            if math.Abs(got-tt.want) > 1e-9 {
+                t.Errorf("kellyStake(%v, %v, %v) = %v, want %v", tt.p, tt.odds, tt.fraction, got, tt.want)
+            }
+        })
+    }
+}
+
+// The staking plan orders in SQL, so kellyStakeExpr must apply the same cap
+// and fraction as kellyStake.
+func TestKellyStakeExpr(t *testing.T) {
+    expr := kellyStakeExpr(3)
+    if !strings.HasPrefix(expr, fmt.Sprintf("GREATEST(0, LEAST(%g, ", maxKellyStake)) {
+        t.Errorf("kellyStakeExpr is not clamped to [0, maxKellyStake]: %s", expr)
+    }
+    if !strings.HasSuffix(expr, " * $3::float8))") {
+        t.Errorf("kellyStakeExpr does not scale by the bound fraction: %s", expr)
+    }
+}
+
+func TestBankrollSimSkipsInvalidOdds(t *testing.T) {
+    bets := []bankrollBet{
+        {PredictionID: 1, Confidence: 60, Odds: 2, Correct: true},
+        {PredictionID: 2, Confidence: 60, Odds: 1, Correct: true},
+        {PredictionID: 3, Confidence: 60, Odds: 0.5, Correct: false},
+        {PredictionID: 4, Confidence: 60, Odds: 0, Correct: true},
+        {PredictionID: 5, Confidence: 60, Odds: 3, Correct: false},
+    }
+    for _, strategy := range []string{"flat", "kelly"} {
+        sim := newBankrollSim(strategy, 100, 10, 1)
+        for _, bet := range bets {
+            sim.place(bet)
+        }
+        resp := sim.result()
+        var placed []int
+        for _, pt := range resp.Curve {
+            placed = append(placed, pt.PredictionID)
+        }
+        if fmt.Sprint(placed) != "[1 5]" {
+            t.Errorf("%s: placed %v, want only the bets with odds over 1", strategy, placed)
+        }
+        if resp.Bets != 2 {
+            t.Errorf("%s: Bets = %d, want 2", strategy, resp.Bets)
+        }
+    }
+}
+
+func TestBankrollSimFlat(t *testing.T) {
+    sim := newBankrollSim("flat", 25, 10, 1)
+    for i, bet := range []bankrollBet{
+        {Odds: 2.5, Correct: true},  // +15 -> 40
+        {Odds: 1.8, Correct: false}, // -10 -> 30
+        {Odds: 1.5, Correct: false}, // -10 -> 20
+        {Odds: 1.5, Correct: false}, // -10 -> 10
+        {Odds: 1.5, Correct: false}, // -10 -> 0, bust
+        {Odds: 4, Correct: true},    // skipped
+    } {
+        bet.PredictionID = i + 1
+        sim.place(bet)
+    }
+    resp := sim.result()
+    if resp.Final != 0 || !resp.Busted || resp.Bets != 5 {
+        t.Errorf("final %v, busted %v, bets %d; want 0, true, 5", resp.Final, resp.Busted, resp.Bets)
+    }
+    if resp.Curve[0].Profit != 15 || resp.Curve[0].Bankroll != 40 {
+        t.Errorf("first bet = %+v, want +15 to 40", resp.Curve[0])
+    }
+}
+
+func TestBuildBankrollQueryExcludesInvalidOdds(t *testing.T) {
+    query, _ := buildBankrollQuery(filterSet{})
+    _, where, _ := strings.Cut(query, " WHERE ")
+    if !strings.Contains(where, "NOT "+invalidOddsExpr) {
+        t.Errorf("bankroll query keeps rows with invalid odds: %s", query)
+    }
+}
+
+func TestUnitStakeReturn(t *testing.T) {
+    tests := []struct {
+        name   string
+        ret    unitStakeReturn
+        profit float64
+        roi    *float64
+    }{
+        {"no bets", unitStakeReturn{}, 0, nil},
+        {"all lost", unitStakeReturn{Lost: 4}, -4, floatPtr(-1)},
+        {"break even", unitStakeReturn{Won: 1, Lost: 1, Winnings: 1}, 0, floatPtr(0)},
+        {"profitable", unitStakeReturn{Won: 2, Lost: 2, Winnings: 3}, 1, floatPtr(0.25)},
+    }
+    for _, tt := range tests {
+        if got := tt.ret.profit(); math.Abs(got-tt.profit) > 1e-9 {
+            t.Errorf("%s: profit = %v, want %v", tt.name, got, tt.profit)
+        }
+        got := tt.ret.roi()
+        if (got == nil) != (tt.roi == nil) || got != nil && math.Abs(*got-*tt.roi) > 1e-9 {
+            t.Errorf("%s: roi = %v, want %v", tt.name, got, tt.roi)
+        }
+    }
+}
+
+// Every ROI aggregate must leave invalid-odds rows out of both the
+// winnings and the bet counts.
+func TestUnitStakeColumnsExcludeInvalidOdds(t *testing.T) {
+    cols := unitStakeColumns()
+    if n := strings.Count(cols, "NOT "+invalidOddsExpr); n != 3 {
+        t.Errorf("invalid odds excluded from %d of 3 aggregates: %s", n, cols)
+    }
+}
+
+func floatPtr(f float64) *float64 { return &f }
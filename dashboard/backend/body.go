@@ -0,0 +1,88 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "mime"
+    "net/http"
+    "strings"
+)
+
+// maxBodyBytes bounds JSON request bodies.
+const maxBodyBytes = 1 << 20
+
+// bodyError is a request body problem with the status to report it with.
+type bodyError struct {
+    status int
+    msg    string
+}
+
+func (e *bodyError) Error() string { return e.msg }
+
+// decodeJSONBody decodes a single JSON value from r's body into dst,
+// rejecting unknown fields, empty bodies, trailing data and bodies over
+// maxBodyBytes. Callers respond with the returned status and message:
+//
+//    if err := decodeJSONBody(w, r, &req); err != nil {
+//        respondError(w, err.status, err.msg)
+//        return
+//    }
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) *bodyError {
+    r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+    dec := json.NewDecoder(r.Body)
+    dec.DisallowUnknownFields()
+
+    if err := dec.Decode(dst); err != nil {
+        var syntaxErr *json.SyntaxError
+        var typeErr *json.UnmarshalTypeError
+        var maxErr *http.MaxBytesError
+        switch {
+        case errors.Is(err, io.EOF):
+            return &bodyError{http.StatusBadRequest, "request body must not be empty"}
+        case errors.As(err, &syntaxErr):
+            return &bodyError{http.StatusBadRequest, fmt.Sprintf("malformed JSON at byte %d", syntaxErr.Offset)}
+        case errors.Is(err, io.ErrUnexpectedEOF):
+            return &bodyError{http.StatusBadRequest, "malformed JSON: body ended early"}
+        case errors.As(err, &typeErr):
+            if typeErr.Field != "" {
+                return &bodyError{http.StatusBadRequest, fmt.Sprintf("field %q has the wrong type (got %s)", typeErr.Field, typeErr.Value)}
+            }
+            return &bodyError{http.StatusBadRequest, fmt.Sprintf("request body has the wrong type (got %s)", typeErr.Value)}
+        case strings.HasPrefix(err.Error(), "json: unknown field "):
+            // encoding/json has no typed error for DisallowUnknownFields.
+            return &bodyError{http.StatusBadRequest, "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")}
+        case errors.As(err, &maxErr):
+            return &bodyError{http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must be at most %d bytes", maxErr.Limit)}
+        default:
+            return &bodyError{http.StatusBadRequest, "invalid request body"}
+        }
+    }
+    // More() misses a stray closing } or ], so decode again and require the
+    // body to end here.
+    if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+        var maxErr *http.MaxBytesError
+        if errors.As(err, &maxErr) {
+            return &bodyError{http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must be at most %d bytes", maxErr.Limit)}
+        }
+        return &bodyError{http.StatusBadRequest, "request body must contain a single JSON value"}
+    }
+    return nil
+}
+
+// requireJSON rejects write requests whose body isn't declared as
+// application/json with 415, so form posts aren't half-understood. Requests
+// with no body, such as bare action POSTs, pass through.
+func requireJSON(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.ContentLength != 0 {
+            mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+            if err != nil || mediaType != "application/json" {
+                respondError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+                return
+            }
+        }
+        next.ServeHTTP(w, r)
+    })
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// decodeTarget mirrors the shape of the by-matches request body.
+type decodeTarget struct {
+    MatchIDs []int `json:"match_ids"`
+}
+
+// serveDecode runs decodeJSONBody the way a handler would and returns the
+// recorded response.
+func serveDecode(body string) *httptest.ResponseRecorder {
+    rec := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+    var dst decodeTarget
+    if err := decodeJSONBody(rec, req, &dst); err != nil {
+        respondError(rec, err.status, err.msg)
+        return rec
+    }
+    respondJSON(rec, dst)
+    return rec
+}
+
+func errorMessage(t *testing.T, rec *httptest.ResponseRecorder) string {
+    t.Helper()
+    var body map[string]string
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+        t.Fatalf("decoding error body %q: %v", rec.Body.String(), err)
+    }
+    return body["error"]
+}
+
+func TestDecodeJSONBodyMalformed(t *testing.T) {
+    tests := []struct {
+        name   string
+        body   string
+        status int
+        msg    string
+    }{
+        {"empty", "", http.StatusBadRequest, "request body must not be empty"},
+        {"syntax", `{"match_ids": [1,}`, http.StatusBadRequest, "malformed JSON at byte 18"},
+        {"truncated", `{"match_ids": [1`, http.StatusBadRequest, "malformed JSON: body ended early"},
+        {"type mismatch", `{"match_ids": "1"}`, http.StatusBadRequest, `field "match_ids" has the wrong type (got string)`},
+        {"wrong top-level type", `[1, 2]`, http.StatusBadRequest, "request body has the wrong type (got array)"},
+        {"unknown field", `{"match_ids": [1], "matchIds": [2]}`, http.StatusBadRequest, `unknown field "matchIds"`},
+        {"trailing data", `{"match_ids": [1]} {}`, http.StatusBadRequest, "request body must contain a single JSON value"},
+        {"stray closing brace", `{"match_ids":[]}}`, http.StatusBadRequest, "request body must contain a single JSON value"},
+        {"stray closing bracket", `{"match_ids":[]}]`, http.StatusBadRequest, "request body must contain a single JSON value"},
+        {"oversize", `{"match_ids": [` + strings.Repeat("1,", maxBodyBytes) + `1]}`,
+            http.StatusRequestEntityTooLarge, "request body must be at most 1048576 bytes"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rec := serveDecode(tt.body)
+            if rec.Code != tt.status {
+                t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body.String())
+            }
+            if got := errorMessage(t, rec); got != tt.msg {
+                t.Errorf("message = %q, want %q", got, tt.msg)
+            }
+        })
+    }
+}
+
+func TestDecodeJSONBodyValid(t *testing.T) {
+    for _, body := range []string{`{"match_ids": [1, 2]}`, "{\"match_ids\": [1, 2]}\n"} {
+        rec := serveDecode(body)
+        if rec.Code != http.StatusOK {
+            t.Fatalf("%q: status = %d, want 200 (body %s)", body, rec.Code, rec.Body.String())
+        }
+    }
+}
+
+func TestRequireJSON(t *testing.T) {
+    next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusNoContent)
+    })
+    tests := []struct {
+        name        string
+        body        string
+        contentType string
+        status      int
+    }{
+        {"json", `{}`, "application/json", http.StatusNoContent},
+        {"json with charset", `{}`, "application/json; charset=utf-8", http.StatusNoContent},
+        {"form", `a=b`, "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+        {"text", `{}`, "text/plain", http.StatusUnsupportedMediaType},
+        {"missing", `{}`, "", http.StatusUnsupportedMediaType},
+        {"no body", ``, "", http.StatusNoContent},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+            if tt.contentType != "" {
+                req.Header.Set("Content-Type", tt.contentType)
+            }
+            rec := httptest.NewRecorder()
+            requireJSON(next).ServeHTTP(rec, req)
+            if rec.Code != tt.status {
+                t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+            }
+            if tt.status == http.StatusUnsupportedMediaType {
+                if got := errorMessage(t, rec); got != "Content-Type must be application/json" {
+                    t.Errorf("message = %q", got)
+                }
+            }
+        })
+    }
+}
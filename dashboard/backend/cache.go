@@ -0,0 +1,75 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// filtersCache holds the /api/filters lists for a short TTL; the distinct
+// scans behind them are the slowest part of loading the dashboard and the
+// values change at most a few times a day. A zero TTL disables caching.
+type filtersCache struct {
+    ttl time.Duration
+
+    mu      sync.Mutex
+    value   filtersResponse
+    expires time.Time
+
+    hits   atomic.Int64
+    misses atomic.Int64
+}
+
+func newFiltersCache(ttl time.Duration) *filtersCache {
+    return &filtersCache{ttl: ttl}
+}
+
+// get returns the cached lists, calling load on a miss or after expiry.
+func (c *filtersCache) get(ctx context.Context, load func(context.Context) (filtersResponse, error)) (filtersResponse, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if c.ttl > 0 && time.Now().Before(c.expires) {
+        c.hits.Add(1)
+        return c.value, nil
+    }
+    c.misses.Add(1)
+
+    value, err := load(ctx)
+    if err != nil {
+        return value, err
+    }
+    c.value = value
+    c.expires = time.Now().Add(c.ttl)
+    return value, nil
+}
+
+type cacheStats struct {
+    Hits       int64    `json:"hits"`
+    Misses     int64    `json:"misses"`
+    HitRate    *float64 `json:"hit_rate"`
+    TTLSeconds int      `json:"ttl_seconds"`
+}
+
+type cacheStatsResponse struct {
+    Filters cacheStats `json:"filters"`
+}
+
+func (c *filtersCache) stats() cacheStats {
+    st := cacheStats{
+        Hits:       c.hits.Load(),
+        Misses:     c.misses.Load(),
+        TTLSeconds: int(c.ttl / time.Second),
+    }
+    if total := st.Hits + st.Misses; total > 0 {
+        rate := roundTo(float64(st.Hits)/float64(total), ratioDecimals)
+        st.HitRate = &rate
+    }
+    return st
+}
+
+func (s *server) handleCacheStats(w http.ResponseWriter, r *http.Request) {
+    respondJSON(w, cacheStatsResponse{Filters: s.filtersCache.stats()})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+    "fmt"
+    "net/http"
+    "strings"
+)
+
+// cacheRule is the Cache-Control policy for paths under prefix. A maxAge
+// of 0 or less means no-store.
+type cacheRule struct {
+    prefix string
+    maxAge int
+}
+
+// cacheRules builds the policy from env, most specific prefix first. Live
+// data and prediction lists are never cached; stats, filters (including
+// the surface list) and facets may be cached publicly (for a CDN) for
+// CACHE_MAX_AGE_STATS, CACHE_MAX_AGE_FILTERS and CACHE_MAX_AGE_FACETS seconds.
+func cacheRules() []cacheRule {
+    return []cacheRule{
+        {"/api/stats/cache", 0},
+        {"/api/stats/", envInt("CACHE_MAX_AGE_STATS", 60)},
+        {"/api/filters", envInt("CACHE_MAX_AGE_FILTERS", 60)},
+        {"/api/surfaces", envInt("CACHE_MAX_AGE_FILTERS", 60)},
+        {"/api/facets/", envInt("CACHE_MAX_AGE_FACETS", 60)},
+        {"/api/live/", 0},
+        {"/api/predictions", 0},
+    }
+}
+
+// cacheHeaders sets Cache-Control by path prefix (after basePath). Error
+// responses are always no-store so a CDN doesn't hold on to them. Paths
+// with no rule get no header.
+func cacheHeaders(basePath string, rules []cacheRule) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            path := strings.TrimPrefix(r.URL.Path, basePath)
+            for _, rule := range rules {
+                if strings.HasPrefix(path, rule.prefix) {
+                    value := "no-store"
+                    if rule.maxAge > 0 {
+                        value = fmt.Sprintf("public, max-age=%d", rule.maxAge)
+                    }
+                    w = &cacheControlWriter{ResponseWriter: w, value: value}
+                    break
+                }
+            }
+            next.ServeHTTP(w, r)
+        })
+    }
+}
+
+type cacheControlWriter struct {
+    http.ResponseWriter
+    value       string
+    wroteHeader bool
+}
+
+func (w *cacheControlWriter) WriteHeader(status int) {
+    if !w.wroteHeader {
+        w.wroteHeader = true
+        if status >= 200 && status < 300 {
+            w.Header().Set("Cache-Control", w.value)
+        } else {
+            w.Header().Set("Cache-Control", "no-store")
+        }
+    }
+    w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+    if !w.wroteHeader {
+        w.WriteHeader(http.StatusOK)
+    }
+    return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
+
+// Flush keeps streaming handlers working through the wrapper.
+func (w *cacheControlWriter) Flush() {
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        if !w.wroteHeader {
+            w.WriteHeader(http.StatusOK)
+        }
+        f.Flush()
+    }
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/go-chi/chi/v5"
+)
+
+// calibrationBinWidth is the width, in confidence points, of each
+// calibration bin.
+const calibrationBinWidth = 10
+
+// calibrationBin compares how confident the model was with how often it was
+// right over resolved predictions in one confidence range. A positive Gap
+// means the model was underconfident there, a negative one overconfident.
+type calibrationBin struct {
+    Label         string  `json:"label"`
+    MinConfidence int     `json:"min_confidence"`
+    MaxConfidence int     `json:"max_confidence"`
+    Count         int     `json:"count"`
+    Correct       int     `json:"correct"`
+    AvgConfidence float64 `json:"avg_confidence"`
+    WinRate       float64 `json:"win_rate"`
+    Gap           float64 `json:"gap"`
+}
+
+// loadCalibration bins the resolved predictions matching clauses by
+// confidence. Empty bins are omitted.
+func (s *server) loadCalibration(ctx context.Context, clauses []string, args []any) ([]calibrationBin, error) {
+    clauses = append(clauses, actualWinnerExpr+" IS NOT NULL")
+
+    base := strings.Builder{}
+    base.WriteString(fmt.Sprintf(`SELECT
+        LEAST(p.confidence_score / %d, %d) AS bin,
+        COUNT(*),
+        COUNT(*) FILTER (WHERE `+correctExpr+`),
+        AVG(p.confidence_score)::float8
+        `+predictionsFrom, calibrationBinWidth, 100/calibrationBinWidth-1))
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY bin ORDER BY bin")
+
+    rows, err := s.replica.Query(ctx, base.String(), args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    bins := []calibrationBin{}
+    for rows.Next() {
+        var bin int
+        var b calibrationBin
+        if err := rows.Scan(&bin, &b.Count, &b.Correct, &b.AvgConfidence); err != nil {
+            return nil, err
+        }
+        b.finish(bin)
+        bins = append(bins, b)
+    }
+    return bins, rows.Err()
+}
+
+// finish fills in a scanned bin's range, label and derived rates.
+func (b *calibrationBin) finish(bin int) {
+    b.MinConfidence = bin * calibrationBinWidth
+    b.MaxConfidence = b.MinConfidence + calibrationBinWidth - 1
+    // A score of 100 is folded into the top bin so it doesn't sit alone.
+    if b.MinConfidence+calibrationBinWidth >= 100 {
+        b.MaxConfidence = 100
+    }
+    b.Label = fmt.Sprintf("%d-%d", b.MinConfidence, b.MaxConfidence)
+    b.WinRate = accuracy(b.Correct, b.Count)
+    b.Gap = roundTo(b.WinRate-b.AvgConfidence/100, ratioDecimals)
+    b.AvgConfidence = roundTo(b.AvgConfidence, 2)
+}
+
+type playerCalibrationResponse struct {
+    Player string           `json:"player"`
+    Data   []calibrationBin `json:"data"`
+}
+
+// handlePlayerCalibration returns calibration bins for the predictions that
+// picked the given player to win, within the usual filters.
+func (s *server) handlePlayerCalibration(w http.ResponseWriter, r *http.Request) {
+    player, err := url.PathUnescape(chi.URLParam(r, "name"))
+    if err != nil || strings.TrimSpace(player) == "" {
+        respondError(w, http.StatusBadRequest, "invalid player name")
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    clauses, args := buildWhereClauses(filters)
+    args = append(args, player)
+    clauses = append(clauses, fmt.Sprintf("p.predicted_winner = $%d", len(args)))
+
+    bins, err := s.loadCalibration(r.Context(), clauses, args)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, playerCalibrationResponse{Player: player, Data: bins})
+}
+
+// calibrationGranularities are the date_trunc units calibration-over-time
+// accepts.
+var calibrationGranularities = map[string]bool{"week": true, "month": true, "quarter": true, "year": true}
+
+// calibrationPeriodBin is one calibration bin within one period, flattened
+// so the frontend can pivot on period and label.
+type calibrationPeriodBin struct {
+    Period string `json:"period"`
+    calibrationBin
+}
+
+type calibrationOverTimeResponse struct {
+    Granularity string                 `json:"granularity"`
+    Data        []calibrationPeriodBin `json:"data"`
+}
+
+// handleCalibrationOverTime groups resolved predictions by prediction_day
+// period and confidence bin, so calibration can be compared as the model
+// learns. granularity is week, month (default), quarter or year; period is
+// the first day of each. Empty cells are omitted.
+func (s *server) handleCalibrationOverTime(w http.ResponseWriter, r *http.Request) {
+    granularity := strings.ToLower(strings.TrimSpace(paramGranularity.get(r)))
+    if granularity == "" {
+        granularity = "month"
+    }
+    if !calibrationGranularities[granularity] {
+        respondError(w, http.StatusBadRequest, "granularity must be week, month, quarter or year")
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, actualWinnerExpr+" IS NOT NULL", "p.prediction_day IS NOT NULL")
+    args = append(args, granularity)
+
+    base := strings.Builder{}
+    base.WriteString(fmt.Sprintf(`SELECT
+        date_trunc($%d, p.prediction_day::timestamp)::date AS period,
+        LEAST(p.confidence_score / %d, %d) AS bin,
+        COUNT(*),
+        COUNT(*) FILTER (WHERE `+correctExpr+`),
+        AVG(p.confidence_score)::float8
+        `+predictionsFrom, len(args), calibrationBinWidth, 100/calibrationBinWidth-1))
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY period, bin ORDER BY period, bin")
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    resp := calibrationOverTimeResponse{Granularity: granularity, Data: []calibrationPeriodBin{}}
+    for rows.Next() {
+        var period time.Time
+        var bin int
+        var b calibrationPeriodBin
+        if err := rows.Scan(&period, &bin, &b.Count, &b.Correct, &b.AvgConfidence); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        b.Period = period.Format("2006-01-02")
+        b.finish(bin)
+        resp.Data = append(resp.Data, b)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, resp)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+    "compress/flate"
+    "net/http"
+    "strings"
+
+    "github.com/go-chi/chi/v5/middleware"
+)
+
+// uncompressedPrefixes are routes that stream their body. Gzip buffers
+// output until it has a full block, which would hold back live updates and
+// large exports, so these are always sent as is.
+var uncompressedPrefixes = []string{
+    "/api/live/",
+    "/api/predictions/export",
+    "/api/admin/export/",
+}
+
+// compressResponses gzips JSON and CSV responses for clients that accept it,
+// except under uncompressedPrefixes. basePath is stripped before matching.
+func compressResponses(basePath string) func(http.Handler) http.Handler {
+    compress := middleware.Compress(flate.DefaultCompression, "application/json", "text/csv", "text/plain")
+    return func(next http.Handler) http.Handler {
+        compressed := compress(next)
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            path := strings.TrimPrefix(r.URL.Path, basePath)
+            for _, prefix := range uncompressedPrefixes {
+                if strings.HasPrefix(path, prefix) {
+                    next.ServeHTTP(w, r)
+                    return
+                }
+            }
+            compressed.ServeHTTP(w, r)
+        })
+    }
+}
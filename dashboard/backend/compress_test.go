@@ -0,0 +1,107 @@
+package main
+
+import (
+    "bufio"
+    "compress/gzip"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+// jsonHandler answers every path with a compressible JSON body.
+var jsonHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    io.WriteString(w, `{"data":"`+strings.Repeat("compressible ", 200)+`"}`)
+})
+
+func TestCompressResponsesSkipsStreams(t *testing.T) {
+    tests := []struct {
+        basePath string
+        path     string
+        gzipped  bool
+    }{
+        {"", "/api/predictions", true},
+        {"", "/api/stats", true},
+        {"", "/api/live/stream", false},
+        {"", "/api/live/matches", false},
+        {"", "/api/predictions/export", false},
+        {"", "/api/predictions/export?format=ndjson", false},
+        {"", "/api/admin/export/full", false},
+        {"/tennis", "/tennis/api/predictions", true},
+        {"/tennis", "/tennis/api/live/stream", false},
+        {"/tennis", "/tennis/api/admin/export/full", false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.basePath+tt.path, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+            req.Header.Set("Accept-Encoding", "gzip")
+            rec := httptest.NewRecorder()
+            compressResponses(tt.basePath)(jsonHandler).ServeHTTP(rec, req)
+
+            encoding := rec.Header().Get("Content-Encoding")
+            if tt.gzipped {
+                if encoding != "gzip" {
+                    t.Fatalf("Content-Encoding = %q, want gzip", encoding)
+                }
+                zr, err := gzip.NewReader(rec.Body)
+                if err != nil {
+                    t.Fatalf("body is not gzip: %v", err)
+                }
+                if _, err := io.ReadAll(zr); err != nil {
+                    t.Fatalf("reading gzip body: %v", err)
+                }
+                return
+            }
+            if encoding != "" {
+                t.Fatalf("Content-Encoding = %q, want none", encoding)
+            }
+            if !strings.HasPrefix(rec.Body.String(), `{"data":`) {
+                t.Errorf("body is not plain JSON: %q", rec.Body.String()[:20])
+            }
+        })
+    }
+}
+
+// A live stream must reach the client event by event, not when the handler
+// returns.
+func TestCompressResponsesStreamsIncrementally(t *testing.T) {
+    release := make(chan struct{})
+    stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/event-stream")
+        io.WriteString(w, "data: first\n\n")
+        w.(http.Flusher).Flush()
+        <-release
+        io.WriteString(w, "data: second\n\n")
+    })
+    srv := httptest.NewServer(compressResponses("")(stream))
+    defer srv.Close()
+    defer close(release)
+
+    req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/live/stream", nil)
+    req.Header.Set("Accept-Encoding", "gzip")
+    resp, err := http.DefaultTransport.RoundTrip(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    if got := resp.Header.Get("Content-Encoding"); got != "" {
+        t.Fatalf("Content-Encoding = %q, want none", got)
+    }
+
+    line := make(chan string, 1)
+    go func() {
+        s, _ := bufio.NewReader(resp.Body).ReadString('\n')
+        line <- s
+    }()
+    select {
+    case s := <-line:
+        if s != "data: first\n" {
+            t.Errorf("first line = %q", s)
+        }
+    case <-time.After(2 * time.Second):
+        t.Fatal("first event not delivered before the handler finished")
+    }
+}
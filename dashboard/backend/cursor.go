@@ -0,0 +1,110 @@
+package main
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strconv"
+    "time"
+)
+
+// pageCursor marks where a page ended for keyset pagination: the sort it
+// was issued under, the last row's sort value (nil for NULL) and its
+// prediction_id, which breaks ties. It is opaque to clients.
+type pageCursor struct {
+    SortBy  string  `json:"s"`
+    SortDir string  `json:"d"`
+    Value   *string `json:"v"`
+    ID      int     `json:"id"`
+}
+
+// cursorSorts maps each sort a cursor can carry to its SQL expression and
+// the type its encoded value is cast back to.
+var cursorSorts = map[string]struct{ expr, cast string }{
+    "prediction_day":                {"p.prediction_day", "date"},
+    "created_at":                    {"p.created_at", "timestamptz"},
+    "confidence_score":              {"p.confidence_score", "int"},
+    "system_accuracy_at_prediction": {"p.system_accuracy_at_prediction", "numeric"},
+    "predicted_odds":                {"(" + predictedOddsExpr + ")::numeric", "numeric"},
+}
+
+// newCursor points just past p under the request's sort.
+func newCursor(filters filterSet, p prediction) pageCursor {
+    c := pageCursor{SortBy: filters.SortBy, SortDir: sortDirOrDefault(filters.SortDir), ID: p.PredictionID}
+    if c.SortBy == "" {
+        c.SortBy = "prediction_day"
+    }
+    var v string
+    switch c.SortBy {
+    case "prediction_day":
+        if p.PredictionDay == nil {
+            return c
+        }
+        v = p.PredictionDay.Format("2006-01-02")
+    case "created_at":
+        if p.CreatedAt == nil {
+            return c
+        }
+        v = p.CreatedAt.Format(time.RFC3339Nano)
+    case "confidence_score":
+        v = strconv.Itoa(p.ConfidenceScore)
+    case "system_accuracy_at_prediction":
+        if p.SystemAccuracyAtPrediction == nil {
+            return c
+        }
+        v = strconv.FormatFloat(*p.SystemAccuracyAtPrediction, 'f', -1, 64)
+    case "predicted_odds":
+        v = strconv.FormatFloat(p.predictedOdds(), 'f', -1, 64)
+    }
+    c.Value = &v
+    return c
+}
+
+func (c pageCursor) encode() string {
+    data, _ := json.Marshal(c)
+    return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (pageCursor, error) {
+    var c pageCursor
+    data, err := base64.RawURLEncoding.DecodeString(raw)
+    if err != nil {
+        return c, err
+    }
+    if err := json.Unmarshal(data, &c); err != nil {
+        return c, err
+    }
+    if _, ok := cursorSorts[c.SortBy]; !ok || sanitizeSortDir(c.SortDir) != c.SortDir || c.SortDir == "" {
+        return c, errors.New("unsupported cursor sort")
+    }
+    return c, nil
+}
+
+// clause returns the WHERE condition selecting rows after the cursor,
+// binding its values after args. It follows Postgres' default NULL
+// placement: NULLS FIRST for DESC, NULLS LAST for ASC.
+func (c pageCursor) clause(args []any) (string, []any) {
+    key := cursorSorts[c.SortBy]
+    col := key.expr
+    cmp := "<"
+    if c.SortDir == "ASC" {
+        cmp = ">"
+    }
+    args = append(args, c.ID)
+    id := fmt.Sprintf("p.prediction_id %s $%d", cmp, len(args))
+
+    if c.Value == nil {
+        if c.SortDir == "ASC" {
+            return fmt.Sprintf("(%s IS NULL AND %s)", col, id), args
+        }
+        return fmt.Sprintf("((%s IS NULL AND %s) OR %s IS NOT NULL)", col, id, col), args
+    }
+    args = append(args, *c.Value)
+    value := fmt.Sprintf("$%d::%s", len(args), key.cast)
+    after := fmt.Sprintf("(%s %s %s OR (%s = %s AND %s))", col, cmp, value, col, value, id)
+    if c.SortDir == "ASC" {
+        return fmt.Sprintf("(%s OR %s IS NULL)", after, col), args
+    }
+    return after, args
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "time"
+
+    "golang.org/x/sync/errgroup"
+)
+
+type systemStatus struct {
+    DaysOperated    int        `json:"days_operated"`
+    LearningPhase   string     `json:"learning_phase"`
+    OverallAccuracy *float64   `json:"overall_accuracy"`
+    LastUpdate      *time.Time `json:"last_update"`
+}
+
+type dashboardResponse struct {
+    Recent      predictionRows  `json:"recent"`
+    Filters     filtersResponse `json:"filters"`
+    Stats       overallStats    `json:"stats"`
+    System      systemStatus    `json:"system"`
+    LiveMatches int             `json:"live_matches"`
+}
+
+// handleDashboard composes everything the landing page needs in one round
+// trip. The pieces are independent, so they run concurrently and the first
+// failure cancels the rest.
+func (s *server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    recentSize := parseIntQuery(r, paramPageSize, 10)
+    if recentSize < 1 || recentSize > 50 {
+        recentSize = 10
+    }
+
+    var resp dashboardResponse
+    g, ctx := errgroup.WithContext(r.Context())
+
+    g.Go(func() error {
+        query, args := buildPredictionQuery(filters, 1, recentSize)
+        recent, err := s.fetchPredictions(ctx, query, args)
+        resp.Recent = newPredictionRows(r, recent)
+        return err
+    })
+    g.Go(func() error {
+        f, err := s.filtersCache.get(ctx, s.loadFilters)
+        resp.Filters = f
+        return err
+    })
+    g.Go(func() error {
+        st, err := s.loadOverallStats(ctx, filters)
+        resp.Stats = st
+        return err
+    })
+    g.Go(func() error {
+        sys, err := s.loadSystemStatus(ctx)
+        resp.System = sys
+        return err
+    })
+    g.Go(func() error {
+        n, err := s.countLiveMatches(ctx)
+        resp.LiveMatches = n
+        return err
+    })
+
+    if err := g.Wait(); err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    respondJSON(w, resp)
+}
+
+// loadSystemStatus reads the single system_metadata row.
+func (s *server) loadSystemStatus(ctx context.Context) (systemStatus, error) {
+    var st systemStatus
+    err := s.db.QueryRow(ctx, `SELECT days_operated, learning_phase, overall_accuracy, last_update
+        FROM system_metadata WHERE id = 1`).Scan(&st.DaysOperated, &st.LearningPhase, &st.OverallAccuracy, &st.LastUpdate)
+    return st, err
+}
+
+// countLiveMatches counts matches the live feed currently reports in play,
+// which is none when live data is disabled.
+func (s *server) countLiveMatches(ctx context.Context) (int, error) {
+    var n int
+    if !liveDataEnabled {
+        return n, nil
+    }
+    err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM live_matches WHERE live_status = 'live'`).Scan(&n)
+    return n, err
+}
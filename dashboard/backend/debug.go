@@ -0,0 +1,101 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "sync/atomic"
+
+    "github.com/jackc/pgx/v5"
+)
+
+type queryCounterKey struct{}
+
+// queryCounter is a pgx tracer that counts queries issued under a context
+// carrying a counter from countQueries. Other queries pass straight through.
+type queryCounter struct{}
+
+func (queryCounter) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+    if n, ok := ctx.Value(queryCounterKey{}).(*atomic.Int64); ok {
+        n.Add(1)
+    }
+    return ctx
+}
+
+func (queryCounter) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+// countQueries reports how many DB queries each request ran, as an
+// X-DB-Queries header and in the log. The header is set when the response
+// starts, so it covers the queries made before the first write; streaming
+// handlers also get the final count logged.
+func countQueries(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        n := new(atomic.Int64)
+        cw := &queryCountWriter{ResponseWriter: w, queries: n}
+        next.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), queryCounterKey{}, n)))
+        log.Printf("%s %s db_queries=%d", r.Method, r.URL.Path, n.Load())
+    })
+}
+
+type queryCountWriter struct {
+    http.ResponseWriter
+    queries     *atomic.Int64
+    wroteHeader bool
+}
+
+func (w *queryCountWriter) WriteHeader(status int) {
+    if !w.wroteHeader {
+        w.wroteHeader = true
+        w.Header().Set("X-DB-Queries", strconv.FormatInt(w.queries.Load(), 10))
+    }
+    w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *queryCountWriter) Write(b []byte) (int, error) {
+    if !w.wroteHeader {
+        w.WriteHeader(http.StatusOK)
+    }
+    return w.ResponseWriter.Write(b)
+}
+
+func (w *queryCountWriter) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
+
+// Flush keeps streaming handlers working through the wrapper.
+func (w *queryCountWriter) Flush() {
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        if !w.wroteHeader {
+            w.WriteHeader(http.StatusOK)
+        }
+        f.Flush()
+    }
+}
+
+type debugFiltersResponse struct {
+    Filters filterSet `json:"filters"`
+    Errors  []string  `json:"errors"`
+    Where   []string  `json:"where"`
+    Args    []any     `json:"args"`
+}
+
+// handleDebugFilters echoes how collectFilters parsed the request, with
+// unset optional filters as null, plus the WHERE clauses and args they
+// produce. Parse errors are listed rather than returned as a 400 so the
+// partial result can still be inspected.
+func handleDebugFilters(w http.ResponseWriter, r *http.Request) {
+    resp := debugFiltersResponse{Errors: []string{}}
+    filters, err := collectFilters(r)
+    if err != nil {
+        var problems filterErrors
+        if !errors.As(err, &problems) {
+            problems = filterErrors{err.Error()}
+        }
+        resp.Errors = problems
+    }
+    resp.Filters = filters
+    resp.Where, resp.Args = buildWhereClauses(filters)
+    respondJSON(w, resp)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// maxExportRows bounds a single filtered export.
+const maxExportRows = 100000
+
+var exportColumns = []string{
+    "prediction_id", "match_id", "prediction_day", "tournament", "surface",
+    "player1", "player2", "odds_player1", "odds_player2", "predicted_winner",
+    "confidence_score", "value_bet", "recommended_action", "actual_winner",
+    "prediction_correct", "confidence_bucket", "created_at",
+}
+
+// handleExportPredictions streams every prediction matching the filters, in
+// the requested sort, as CSV (default) or NDJSON (format=ndjson).
+//
+// An empty result is still a valid file: CSV gets its header row, NDJSON an
+// empty body. Both set X-Result-Count: 0 so importers can tell "no rows"
+// from a failed download.
+func (s *server) handleExportPredictions(w http.ResponseWriter, r *http.Request) {
+    format, ok := exportFormat(w, r)
+    if !ok {
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    base := strings.Builder{}
+    writePredictionSelectFrom(&base, filters.IncludeOddsHistory, scopedPredictionsFrom(filters))
+    clauses, args := buildWhereClauses(filters)
+    writeWhere(&base, clauses)
+    args = writeOrderBy(&base, filters, args)
+    args = append(args, maxExportRows)
+    base.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+    writePredictionExport(w, format, rows)
+}
+
+// writePredictionExport writes rows as a format export. It peeks at the
+// first row so a query error still gets a proper status and an empty export
+// can be flagged before the body starts.
+func writePredictionExport(w http.ResponseWriter, format string, rows pgx.Rows) {
+    now := time.Now()
+    hasRows := rows.Next()
+    if !hasRows && rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    if format == "ndjson" {
+        w.Header().Set("Content-Type", "application/x-ndjson")
+    } else {
+        w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+    }
+    w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="predictions.%s"`, format))
+    if !hasRows {
+        w.Header().Set("X-Result-Count", "0")
+    }
+    w.WriteHeader(http.StatusOK)
+
+    var writeRow func(prediction) error
+    var flush func()
+    if format == "ndjson" {
+        enc := json.NewEncoder(w)
+        writeRow = func(p prediction) error { return enc.Encode(p) }
+        flush = func() {}
+    } else {
+        cw := csv.NewWriter(w)
+        if err := cw.Write(exportColumns); err != nil {
+            return
+        }
+        writeRow = func(p prediction) error { return cw.Write(predictionCSVRecord(p)) }
+        flush = cw.Flush
+    }
+    defer flush()
+
+    for ok := hasRows; ok; ok = rows.Next() {
+        p, err := scanPrediction(rows, now)
+        if err != nil {
+            // Headers are gone; all we can do is stop and log.
+            httpErrorLog(err)
+            return
+        }
+        if err := writeRow(p); err != nil {
+            return
+        }
+    }
+    if rows.Err() != nil {
+        httpErrorLog(rows.Err())
+    }
+}
+
+// exportFormat reads format=csv (default) or ndjson, answering 400 and
+// returning false for anything else.
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+    format := strings.ToLower(strings.TrimSpace(paramFormat.get(r)))
+    if format == "" {
+        format = "csv"
+    }
+    if format != "csv" && format != "ndjson" {
+        respondError(w, http.StatusBadRequest, "format must be csv or ndjson")
+        return "", false
+    }
+    return format, true
+}
+
+// fullExportFetchSize is how many rows each FETCH pulls from the full
+// export's cursor, which bounds the memory one export holds.
+const fullExportFetchSize = 1000
+
+// handleExportFull streams the raw predictions table, every column and
+// every row including archived ones, as CSV (default) or NDJSON for
+// disaster-recovery snapshots. It reads through a server-side cursor in a
+// read-only repeatable-read transaction, so the file is one consistent
+// snapshot and memory stays bounded however large the table is.
+func (s *server) handleExportFull(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+    format, ok := exportFormat(w, r)
+    if !ok {
+        return
+    }
+
+    tx, err := s.replica.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer tx.Rollback(ctx)
+
+    // Column order for the CSV header, straight from the table.
+    rows, err := tx.Query(ctx, "SELECT * FROM predictions LIMIT 0")
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    var columns []string
+    for _, fd := range rows.FieldDescriptions() {
+        columns = append(columns, fd.Name)
+    }
+    rows.Close()
+
+    // row_to_json keeps every column's type without a Go mapping per column.
+    if _, err := tx.Exec(ctx, `DECLARE full_export NO SCROLL CURSOR FOR
+        SELECT row_to_json(p)::text FROM predictions p ORDER BY p.prediction_id`); err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    fetch := func() ([]string, error) {
+        rows, err := tx.Query(ctx, fmt.Sprintf("FETCH FORWARD %d FROM full_export", fullExportFetchSize))
+        if err != nil {
+            return nil, err
+        }
+        return pgx.CollectRows(rows, pgx.RowTo[string])
+    }
+
+    // Fetch the first batch before the headers so errors get a real status.
+    batch, err := fetch()
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    if format == "ndjson" {
+        w.Header().Set("Content-Type", "application/x-ndjson")
+    } else {
+        w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+    }
+    stamp := time.Now().UTC().Format("20060102T150405Z")
+    w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="predictions-full-%s.%s"`, stamp, format))
+    if len(batch) == 0 {
+        w.Header().Set("X-Result-Count", "0")
+    }
+    w.WriteHeader(http.StatusOK)
+
+    var writeRow func(string) error
+    var flush func()
+    if format == "ndjson" {
+        writeRow = func(line string) error {
+            _, err := io.WriteString(w, line+"\n")
+            return err
+        }
+        flush = func() {}
+    } else {
+        cw := csv.NewWriter(w)
+        if err := cw.Write(columns); err != nil {
+            return
+        }
+        writeRow = func(line string) error {
+            record, err := jsonRowCSVRecord(line, columns)
+            if err != nil {
+                return err
+            }
+            return cw.Write(record)
+        }
+        flush = cw.Flush
+    }
+    flusher, _ := w.(http.Flusher)
+
+    for len(batch) > 0 {
+        for _, line := range batch {
+            if err := writeRow(line); err != nil {
+                // Headers are gone; all we can do is stop and log.
+                httpErrorLog(err)
+                return
+            }
+        }
+        flush()
+        if flusher != nil {
+            flusher.Flush()
+        }
+        if batch, err = fetch(); err != nil {
+            httpErrorLog(err)
+            return
+        }
+    }
+}
+
+// jsonRowCSVRecord renders one row_to_json object in columns order. Nulls
+// are empty, strings unquoted, and everything else keeps its JSON text.
+func jsonRowCSVRecord(line string, columns []string) ([]string, error) {
+    var fields map[string]json.RawMessage
+    if err := json.Unmarshal([]byte(line), &fields); err != nil {
+        return nil, err
+    }
+    record := make([]string, len(columns))
+    for i, column := range columns {
+        raw := fields[column]
+        switch {
+        case len(raw) == 0 || string(raw) == "null":
+        case raw[0] == '"':
+            if err := json.Unmarshal(raw, &record[i]); err != nil {
+                return nil, err
+            }
+        default:
+            record[i] = string(raw)
+        }
+    }
+    return record, nil
+}
+
+// predictionCSVRecord renders p in exportColumns order; nulls are empty.
+func predictionCSVRecord(p prediction) []string {
+    return []string{
+        strconv.Itoa(p.PredictionID),
+        p.MatchID,
+        formatDate(p.PredictionDay),
+        p.Tournament,
+        p.Surface,
+        p.Player1,
+        p.Player2,
+        strconv.FormatFloat(p.OddsPlayer1, 'f', -1, 64),
+        strconv.FormatFloat(p.OddsPlayer2, 'f', -1, 64),
+        p.PredictedWinner,
+        strconv.Itoa(p.ConfidenceScore),
+        formatBool(p.ValueBet),
+        derefString(p.RecommendedAction),
+        derefString(p.ActualWinner),
+        formatBool(p.PredictionCorrect),
+        derefString(p.ConfidenceBucket),
+        formatTimestamp(p.CreatedAt),
+    }
+}
+
+func formatDate(t *time.Time) string {
+    if t == nil {
+        return ""
+    }
+    return t.Format("2006-01-02")
+}
+
+func formatTimestamp(t *time.Time) string {
+    if t == nil {
+        return ""
+    }
+    return t.UTC().Format(time.RFC3339)
+}
+
+func formatBool(b *bool) string {
+    if b == nil {
+        return ""
+    }
+    return strconv.FormatBool(*b)
+}
+
+func derefString(s *string) string {
+    if s == nil {
+        return ""
+    }
+    return *s
+}
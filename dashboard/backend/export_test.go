@@ -0,0 +1,77 @@
+package main
+
+import (
+    "encoding/csv"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+)
+
+// emptyRows is a pgx.Rows with no rows, optionally failing with err.
+type emptyRows struct {
+    err error
+}
+
+func (r *emptyRows) Close()                                       {}
+func (r *emptyRows) Err() error                                   { return r.err }
+func (r *emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *emptyRows) Next() bool                                   { return false }
+func (r *emptyRows) Scan(dest ...any) error                       { return errors.New("no rows") }
+func (r *emptyRows) Values() ([]any, error)                       { return nil, errors.New("no rows") }
+func (r *emptyRows) RawValues() [][]byte                          { return nil }
+func (r *emptyRows) Conn() *pgx.Conn                              { return nil }
+
+func TestWritePredictionExportEmptyCSV(t *testing.T) {
+    rec := httptest.NewRecorder()
+    writePredictionExport(rec, "csv", &emptyRows{})
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200", rec.Code)
+    }
+    if got := rec.Header().Get("X-Result-Count"); got != "0" {
+        t.Errorf("X-Result-Count = %q, want 0", got)
+    }
+    records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+    if err != nil {
+        t.Fatalf("parsing CSV: %v", err)
+    }
+    if len(records) != 1 {
+        t.Fatalf("got %d records, want only the header", len(records))
+    }
+    if strings.Join(records[0], ",") != strings.Join(exportColumns, ",") {
+        t.Errorf("header = %v, want %v", records[0], exportColumns)
+    }
+}
+
+func TestWritePredictionExportEmptyNDJSON(t *testing.T) {
+    rec := httptest.NewRecorder()
+    writePredictionExport(rec, "ndjson", &emptyRows{})
+
+    if rec.Code != http.StatusOK {
+        t.Fatalf("status = %d, want 200", rec.Code)
+    }
+    if got := rec.Header().Get("X-Result-Count"); got != "0" {
+        t.Errorf("X-Result-Count = %q, want 0", got)
+    }
+    if rec.Body.Len() != 0 {
+        t.Errorf("body = %q, want empty", rec.Body.String())
+    }
+}
+
+func TestWritePredictionExportQueryError(t *testing.T) {
+    rec := httptest.NewRecorder()
+    writePredictionExport(rec, "csv", &emptyRows{err: errors.New("connection reset")})
+
+    if rec.Code != http.StatusInternalServerError {
+        t.Fatalf("status = %d, want 500", rec.Code)
+    }
+    if got := rec.Header().Get("X-Result-Count"); got != "" {
+        t.Errorf("X-Result-Count = %q on a failed query", got)
+    }
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "strings"
+
+    "github.com/go-chi/chi/v5"
+    "golang.org/x/sync/errgroup"
+)
+
+// maxFacetValues caps how many values each facet returns by default;
+// maxFacetPage caps an explicit limit.
+const (
+    maxFacetValues = 50
+    maxFacetPage   = 500
+)
+
+// facetPage selects a window of facet values: those starting with Prefix
+// (case-insensitive), skipping Offset, at most Limit.
+type facetPage struct {
+    Prefix string
+    Limit  int
+    Offset int
+}
+
+// parseFacetPage reads q, limit and offset. Out-of-range limits fall back
+// to maxFacetValues and negative offsets to 0.
+func parseFacetPage(r *http.Request) facetPage {
+    page := facetPage{
+        Prefix: strings.TrimSpace(paramQ.get(r)),
+        Limit:  parseIntQuery(r, paramLimit, maxFacetValues),
+        Offset: parseIntQuery(r, paramOffset, 0),
+    }
+    if page.Limit < 1 || page.Limit > maxFacetPage {
+        page.Limit = maxFacetValues
+    }
+    if page.Offset < 0 {
+        page.Offset = 0
+    }
+    return page
+}
+
+// pageValues applies page to an already sorted list of values.
+func pageValues(values []string, page facetPage) ([]string, bool) {
+    matched := values
+    if page.Prefix != "" {
+        prefix := strings.ToLower(page.Prefix)
+        matched = []string{}
+        for _, v := range values {
+            if strings.HasPrefix(strings.ToLower(v), prefix) {
+                matched = append(matched, v)
+            }
+        }
+    }
+    if page.Offset >= len(matched) {
+        return []string{}, false
+    }
+    end := page.Offset + page.Limit
+    if end >= len(matched) {
+        return matched[page.Offset:], false
+    }
+    return matched[page.Offset:end], true
+}
+
+type facetCount struct {
+    Value string `json:"value"`
+    Count int    `json:"count"`
+}
+
+// facet is a groupable column plus how to drop its own filters (include and
+// exclude alike), so each facet's counts reflect every other active filter
+// but not itself.
+type facet struct {
+    Name   string
+    Column string
+    Clear  func(*filterSet)
+}
+
+var allFacets = []facet{
+    {Name: "tournament", Column: "p.tournament", Clear: func(f *filterSet) {
+        f.Tournament = ""
+        f.TournamentLike = ""
+        f.ExcludeTournaments = nil
+    }},
+    {Name: "surface", Column: "p.surface", Clear: func(f *filterSet) {
+        f.Surface = ""
+        f.ExcludeSurfaces = nil
+    }},
+    {Name: "learning_phase", Column: "p.learning_phase", Clear: func(f *filterSet) { f.LearningPhase = "" }},
+    {Name: "recommended_action", Column: "p.recommended_action", Clear: func(f *filterSet) { f.RecommendedActions = nil }},
+    {Name: "confidence_bucket", Column: "p.confidence_bucket", Clear: func(f *filterSet) { f.ConfidenceBucket = "" }},
+}
+
+// handleAllFacets returns value counts for every facet in one request. Each
+// facet query runs concurrently.
+func (s *server) handleAllFacets(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    counts := make([][]facetCount, len(allFacets))
+    g, ctx := errgroup.WithContext(r.Context())
+    for i, f := range allFacets {
+        g.Go(func() error {
+            facetFilters := filters
+            f.Clear(&facetFilters)
+            values, _, err := s.facetCounts(ctx, f.Column, facetFilters, facetPage{Limit: maxFacetValues})
+            counts[i] = values
+            return err
+        })
+    }
+    if err := g.Wait(); err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    resp := make(map[string][]facetCount, len(allFacets))
+    for i, f := range allFacets {
+        resp[f.Name] = counts[i]
+    }
+    respondJSON(w, resp)
+}
+
+type facetPageResponse struct {
+    Data    []facetCount `json:"data"`
+    HasMore bool         `json:"has_more"`
+}
+
+// handleFacet pages through one facet's value counts, for dropdowns too
+// long to load at once. It takes the usual filters plus q, limit and offset.
+func (s *server) handleFacet(w http.ResponseWriter, r *http.Request) {
+    name := chi.URLParam(r, "name")
+    var f *facet
+    for i := range allFacets {
+        if allFacets[i].Name == name {
+            f = &allFacets[i]
+        }
+    }
+    if f == nil {
+        respondError(w, http.StatusNotFound, fmt.Sprintf("unknown facet %q", name))
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    f.Clear(&filters)
+
+    values, hasMore, err := s.facetCounts(r.Context(), f.Column, filters, parseFacetPage(r))
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    respondJSON(w, facetPageResponse{Data: values, HasMore: hasMore})
+}
+
+// facetCounts counts non-empty values of column across rows matching
+// filters, most common first, within page. hasMore reports whether values
+// exist beyond it.
+func (s *server) facetCounts(ctx context.Context, column string, filters filterSet, page facetPage) ([]facetCount, bool, error) {
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, column+" IS NOT NULL", column+" != ''")
+    if page.Prefix != "" {
+        args = append(args, page.Prefix)
+        clauses = append(clauses, fmt.Sprintf("starts_with(LOWER(%s), LOWER($%d))", column, len(args)))
+    }
+
+    base := strings.Builder{}
+    base.WriteString(fmt.Sprintf("SELECT %s, COUNT(*) %s", column, predictionsFrom))
+    writeWhere(&base, clauses)
+    // One extra row tells whether there is another page.
+    args = append(args, page.Limit+1, page.Offset)
+    base.WriteString(fmt.Sprintf(" GROUP BY %s ORDER BY COUNT(*) DESC, %s LIMIT $%d OFFSET $%d", column, column, len(args)-1, len(args)))
+
+    rows, err := s.replica.Query(ctx, base.String(), args...)
+    if err != nil {
+        return nil, false, err
+    }
+    defer rows.Close()
+
+    values := []facetCount{}
+    for rows.Next() {
+        var fc facetCount
+        if err := rows.Scan(&fc.Value, &fc.Count); err != nil {
+            return nil, false, err
+        }
+        values = append(values, fc)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, false, err
+    }
+    if len(values) > page.Limit {
+        return values[:page.Limit], true, nil
+    }
+    return values, false, nil
+}
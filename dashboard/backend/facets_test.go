@@ -0,0 +1,65 @@
+package main
+
+import (
+    "strings"
+    "testing"
+)
+
+// allColumnFilters sets every filter that narrows a facet's own column.
+func allColumnFilters() filterSet {
+    return filterSet{
+        Tournament:         "Wimbledon",
+        TournamentLike:     "open",
+        ExcludeTournaments: []string{"ITF M25"},
+        Surface:            "Grass",
+        ExcludeSurfaces:    []string{"Clay"},
+        LearningPhase:      "mature",
+        RecommendedActions: []string{"bet"},
+        ConfidenceBucket:   "high",
+    }
+}
+
+// A facet's Clear must drop every filter on its own column, or a picked or
+// excluded value narrows the list it is picked from.
+func TestFacetClearDropsOwnColumn(t *testing.T) {
+    for _, f := range allFacets {
+        t.Run(f.Name, func(t *testing.T) {
+            filters := allColumnFilters()
+            f.Clear(&filters)
+            clauses, _ := buildWhereClauses(filters)
+            for _, c := range clauses {
+                if strings.Contains(strings.ToLower(c), f.Column) {
+                    t.Errorf("clause %q still filters %s", c, f.Column)
+                }
+            }
+        })
+    }
+}
+
+// Clear must leave the other facets' filters alone.
+func TestFacetClearKeepsOtherColumns(t *testing.T) {
+    full, _ := buildWhereClauses(allColumnFilters())
+    for _, f := range allFacets {
+        filters := allColumnFilters()
+        f.Clear(&filters)
+        clauses, _ := buildWhereClauses(filters)
+        for _, other := range allFacets {
+            if other.Column == f.Column {
+                continue
+            }
+            if countMentions(clauses, other.Column) != countMentions(full, other.Column) {
+                t.Errorf("clearing %s changed the %s filters", f.Name, other.Name)
+            }
+        }
+    }
+}
+
+func countMentions(clauses []string, column string) int {
+    n := 0
+    for _, c := range clauses {
+        if strings.Contains(strings.ToLower(c), column) {
+            n++
+        }
+    }
+    return n
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+    "errors"
+    "log"
+    "net/http"
+
+    "github.com/jackc/pgx/v5/pgconn"
+)
+
+// logFailures writes one log line per response with status >= 400: method,
+// path, query string and status, plus for 5xx the error handed to
+// httpError. 4xx lines are WARN and 5xx ERROR. It is on unless
+// LOG_FAILED_REQUESTS=false.
+func logFailures(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        fw := &failureWriter{ResponseWriter: w}
+        next.ServeHTTP(fw, r)
+        if fw.status < 400 {
+            return
+        }
+        target := r.URL.Path
+        if r.URL.RawQuery != "" {
+            target += "?" + r.URL.RawQuery
+        }
+        switch {
+        case fw.status < 500:
+            log.Printf("WARN %d %s %s", fw.status, r.Method, target)
+        case fw.err != nil:
+            log.Printf("ERROR %d %s %s: %s", fw.status, r.Method, target, describeError(fw.err))
+        default:
+            log.Printf("ERROR %d %s %s", fw.status, r.Method, target)
+        }
+    })
+}
+
+// failureWriter records the response status and the error httpError saw,
+// so logFailures can tie them to the request.
+type failureWriter struct {
+    http.ResponseWriter
+    status int
+    err    error
+}
+
+func (w *failureWriter) WriteHeader(status int) {
+    if w.status == 0 {
+        w.status = status
+    }
+    w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *failureWriter) Write(b []byte) (int, error) {
+    if w.status == 0 {
+        w.status = http.StatusOK
+    }
+    return w.ResponseWriter.Write(b)
+}
+
+func (w *failureWriter) Flush() {
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+}
+
+func (w *failureWriter) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
+
+// recordFailure hands err to the enclosing logFailures, following Unwrap
+// through other middleware's writers. It returns false when there is none.
+func recordFailure(w http.ResponseWriter, err error) bool {
+    for {
+        if fw, ok := w.(*failureWriter); ok {
+            fw.err = err
+            return true
+        }
+        u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+        if !ok {
+            return false
+        }
+        w = u.Unwrap()
+    }
+}
+
+// describeError formats err for the log, labelling Postgres errors.
+func describeError(err error) string {
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) {
+        return "pg error: " + pgErr.Error()
+    }
+    return "error: " + err.Error()
+}
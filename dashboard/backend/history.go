@@ -0,0 +1,79 @@
+package main
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/go-chi/chi/v5"
+    "github.com/jackc/pgx/v5"
+)
+
+// historyChange describes how one prediction differs from the one before
+// it for the same match.
+type historyChange struct {
+    FromID          int    `json:"from_id"`
+    ToID            int    `json:"to_id"`
+    WinnerChanged   bool   `json:"winner_changed"`
+    PreviousWinner  string `json:"previous_winner"`
+    PredictedWinner string `json:"predicted_winner"`
+    ConfidenceDelta int    `json:"confidence_delta"`
+}
+
+type historyResponse struct {
+    MatchID string          `json:"match_id"`
+    Data    predictionRows  `json:"data"`
+    Changes []historyChange `json:"changes"`
+}
+
+// handlePredictionHistory returns every prediction for the same match as
+// the given one, oldest first, with the pick and confidence changes between
+// consecutive entries, so the detail view can show how the model's view
+// evolved before the match. match_id is unique in the current schema, so
+// there data is always the one prediction and changes is always empty;
+// only databases that predate that constraint have a history to show.
+func (s *server) handlePredictionHistory(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "invalid prediction id")
+        return
+    }
+
+    var matchID string
+    err = s.db.QueryRow(ctx, `SELECT match_id FROM predictions WHERE prediction_id = $1`, id).Scan(&matchID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        respondError(w, http.StatusNotFound, "prediction not found")
+        return
+    }
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    base := strings.Builder{}
+    writePredictionSelect(&base, false)
+    base.WriteString(" WHERE p.match_id = $1 ORDER BY p.created_at ASC, p.prediction_id ASC")
+
+    results, err := s.fetchPredictions(ctx, base.String(), []any{matchID})
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    changes := []historyChange{}
+    for i := 1; i < len(results); i++ {
+        prev, cur := results[i-1], results[i]
+        changes = append(changes, historyChange{
+            FromID:          prev.PredictionID,
+            ToID:            cur.PredictionID,
+            WinnerChanged:   prev.PredictedWinner != cur.PredictedWinner,
+            PreviousWinner:  prev.PredictedWinner,
+            PredictedWinner: cur.PredictedWinner,
+            ConfidenceDelta: cur.ConfidenceScore - prev.ConfidenceScore,
+        })
+    }
+
+    respondJSON(w, historyResponse{MatchID: matchID, Data: newPredictionRows(r, results), Changes: changes})
+}
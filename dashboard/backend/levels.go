@@ -0,0 +1,177 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "os"
+    "slices"
+    "strings"
+    "unicode"
+)
+
+// levelRule assigns Level to tournaments whose name contains any of
+// Patterns as whole words, case-insensitively (see nameMatches).
+type levelRule struct {
+    Level    string   `json:"level"`
+    Patterns []string `json:"patterns"`
+}
+
+// otherLevel is the level of tournaments no rule matches.
+const otherLevel = "Other"
+
+// defaultLevelRules is a best-effort mapping from tournament names to
+// levels. Rules are tried in order and the first match wins, so the more
+// specific ones (team events, tour finals, Challengers) come before the
+// bare "1000"/"500"/"250" tier numbers that also appear in their names.
+// Patterns match whole words, so "itf" doesn't catch "Mitford" and "250"
+// doesn't catch a "2500" prize or year in a name.
+var defaultLevelRules = []levelRule{
+    {"Team", []string{"davis cup", "billie jean king", "united cup", "laver cup"}},
+    {"Finals", []string{"atp finals", "wta finals", "tour finals", "next gen"}},
+    {"Grand Slam", []string{"australian open", "roland garros", "roland-garros", "french open", "wimbledon", "us open", "grand slam"}},
+    {"Challenger", []string{"challenger"}},
+    {"ITF", []string{"itf"}},
+    {"1000", []string{"1000", "masters", "indian wells", "miami", "monte carlo", "monte-carlo", "madrid", "internazionali", "cincinnati", "shanghai", "canadian open"}},
+    {"500", []string{"500"}},
+    {"250", []string{"250"}},
+}
+
+// loadLevelRules reads TOURNAMENT_LEVELS_FILE, a JSON list shaped like
+// [{"level": "Grand Slam", "patterns": ["wimbledon"]}], which replaces
+// defaultLevelRules so misclassifications can be fixed without a release.
+func loadLevelRules() ([]levelRule, error) {
+    path := os.Getenv("TOURNAMENT_LEVELS_FILE")
+    if path == "" {
+        return defaultLevelRules, nil
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var rules []levelRule
+    if err := json.Unmarshal(data, &rules); err != nil {
+        return nil, fmt.Errorf("parse tournament levels: %w", err)
+    }
+    for i, rule := range rules {
+        if strings.TrimSpace(rule.Level) == "" {
+            return nil, fmt.Errorf("tournament level rule %d has no level", i)
+        }
+        for j, p := range rule.Patterns {
+            rules[i].Patterns[j] = strings.ToLower(strings.TrimSpace(p))
+        }
+    }
+    return rules, nil
+}
+
+// tournamentLevel classifies a tournament name by the first matching rule.
+func tournamentLevel(rules []levelRule, tournament string) string {
+    name := nameWords(tournament)
+    for _, rule := range rules {
+        for _, p := range rule.Patterns {
+            if nameMatches(name, nameWords(p)) {
+                return rule.Level
+            }
+        }
+    }
+    return otherLevel
+}
+
+// nameWords lower-cases s and splits it into runs of letters and digits,
+// so punctuation and hyphens separate words: "Roland-Garros" and "Roland
+// Garros" are both [roland garros].
+func nameWords(s string) []string {
+    return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+}
+
+// nameMatches reports whether pattern's words appear consecutively in
+// name. An empty pattern matches nothing.
+func nameMatches(name, pattern []string) bool {
+    if len(pattern) == 0 {
+        return false
+    }
+    for i := 0; i+len(pattern) <= len(name); i++ {
+        if slices.Equal(name[i:i+len(pattern)], pattern) {
+            return true
+        }
+    }
+    return false
+}
+
+// handleStatsByLevel groups resolved predictions by tournament level,
+// classified by tournamentLevel over s.levels. Accuracy is computed per
+// tournament in SQL and the classification done here, so the mapping can
+// change without touching the query. Levels come back in rule order with
+// Other last; levels without data are left out.
+func (s *server) handleStatsByLevel(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    clauses, args := buildWhereClauses(filters)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        p.tournament,
+        COUNT(*),
+        COUNT(*) FILTER (WHERE ` + correctExpr + `)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY p.tournament")
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    byLevel := map[string]*accuracyGroup{}
+    for rows.Next() {
+        var tournament string
+        var count, correct int
+        if err := rows.Scan(&tournament, &count, &correct); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        level := tournamentLevel(s.levels, tournament)
+        g, ok := byLevel[level]
+        if !ok {
+            g = &accuracyGroup{Label: level}
+            byLevel[level] = g
+        }
+        g.Count += count
+        g.Correct += correct
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    groups := []accuracyGroup{}
+    for _, level := range levelOrder(s.levels) {
+        if g, ok := byLevel[level]; ok {
+            g.Accuracy = accuracy(g.Correct, g.Count)
+            groups = append(groups, *g)
+        }
+    }
+
+    respondJSON(w, accuracyGroupsResponse{Data: groups})
+}
+
+// levelOrder lists each rule's level once, in rule order, then Other.
+func levelOrder(rules []levelRule) []string {
+    seen := map[string]bool{otherLevel: true}
+    var order []string
+    for _, rule := range rules {
+        if !seen[rule.Level] {
+            seen[rule.Level] = true
+            order = append(order, rule.Level)
+        }
+    }
+    return append(order, otherLevel)
+}
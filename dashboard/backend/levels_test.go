@@ -0,0 +1,119 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestTournamentLevelDefaults(t *testing.T) {
+    tests := []struct {
+        tournament string
+        want       string
+    }{
+        // Grand Slams, under their common spellings.
+        {"Wimbledon", "Grand Slam"},
+        {"Australian Open", "Grand Slam"},
+        {"Roland Garros", "Grand Slam"},
+        {"Roland-Garros", "Grand Slam"},
+        {"French Open", "Grand Slam"},
+        {"US Open", "Grand Slam"},
+        // Masters 1000 by tier number, "masters" or host city.
+        {"ATP Masters 1000 Rome", "1000"},
+        {"Rolex Paris Masters", "1000"},
+        {"BNP Paribas Open Indian Wells", "1000"},
+        {"Miami Open", "1000"},
+        {"Monte-Carlo Rolex Masters", "1000"},
+        {"Mutua Madrid Open", "1000"},
+        {"Internazionali BNL d'Italia", "1000"},
+        {"Western & Southern Open Cincinnati", "1000"},
+        // 500s and 250s only by tier number.
+        {"ATP 500 Hamburg", "500"},
+        {"ATP 250 Stuttgart", "250"},
+        {"WTA 250 - Hobart", "250"},
+        // Specific rules win over tier numbers in the same name.
+        {"ATP Challenger 250 Bergamo", "Challenger"},
+        {"Nitto ATP Finals", "Finals"},
+        {"Next Gen ATP Finals", "Finals"},
+        {"Davis Cup Finals", "Team"},
+        {"Billie Jean King Cup", "Team"},
+        // ITF only as its own word.
+        {"ITF M25 Monastir", "ITF"},
+        {"W15 Antalya (ITF)", "ITF"},
+        {"Mitford Classic", otherLevel},
+        {"Fitfield Open", otherLevel},
+        // Tier numbers only as whole words, not inside years or prizes.
+        {"Open 2500", otherLevel},
+        {"Prize $12500 Cup", otherLevel},
+        {"Summer Series 2025", otherLevel},
+        {"Open 1000000", otherLevel},
+        // "us open" must not match inside other names.
+        {"Brussels Open", otherLevel},
+        {"Exhibition", otherLevel},
+        {"", otherLevel},
+    }
+    for _, tt := range tests {
+        if got := tournamentLevel(defaultLevelRules, tt.tournament); got != tt.want {
+            t.Errorf("tournamentLevel(%q) = %q, want %q", tt.tournament, got, tt.want)
+        }
+    }
+}
+
+func TestTournamentLevelRuleOrder(t *testing.T) {
+    rules := []levelRule{
+        {"Specific", []string{"big open"}},
+        {"General", []string{"open"}},
+        {"Empty", []string{"", "  "}},
+    }
+    tests := []struct {
+        tournament string
+        want       string
+    }{
+        {"The Big Open", "Specific"},
+        {"Big-Open Classic", "Specific"},
+        {"Big City Open", "General"},
+        {"Opening Cup", otherLevel},
+        {"Anything", otherLevel},
+    }
+    for _, tt := range tests {
+        if got := tournamentLevel(rules, tt.tournament); got != tt.want {
+            t.Errorf("tournamentLevel(%q) = %q, want %q", tt.tournament, got, tt.want)
+        }
+    }
+}
+
+func TestLoadLevelRules(t *testing.T) {
+    t.Setenv("TOURNAMENT_LEVELS_FILE", "")
+    rules, err := loadLevelRules()
+    if err != nil || len(rules) != len(defaultLevelRules) {
+        t.Fatalf("without a file: %d rules, err %v; want the defaults", len(rules), err)
+    }
+
+    dir := t.TempDir()
+    path := filepath.Join(dir, "levels.json")
+    if err := os.WriteFile(path, []byte(`[{"level": "Slam", "patterns": [" Wimbledon "]}, {"level": "ITF", "patterns": ["itf"]}]`), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    t.Setenv("TOURNAMENT_LEVELS_FILE", path)
+    rules, err = loadLevelRules()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := tournamentLevel(rules, "WIMBLEDON"); got != "Slam" {
+        t.Errorf("configured rules classify Wimbledon as %q", got)
+    }
+    if got := tournamentLevel(rules, "Mitford"); got != otherLevel {
+        t.Errorf("configured rules classify Mitford as %q", got)
+    }
+    if got := strings.Join(levelOrder(rules), ","); got != "Slam,ITF,"+otherLevel {
+        t.Errorf("levelOrder = %s", got)
+    }
+
+    if err := os.WriteFile(path, []byte(`[{"level": " ", "patterns": ["x"]}]`), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := loadLevelRules(); err == nil {
+        t.Error("a rule with no level was accepted")
+    }
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "time"
+)
+
+type trackedMatch struct {
+    MatchID     string     `json:"match_id"`
+    LastUpdated *time.Time `json:"last_updated"`
+    AgeSeconds  *int       `json:"age_seconds"`
+}
+
+type trackedMatchesResponse struct {
+    Data []trackedMatch `json:"data"`
+}
+
+// handleTrackedMatches lists the in-progress matches the live poller should
+// keep refreshing, stalest first, with how long since each was updated.
+func (s *server) handleTrackedMatches(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+
+    // match_identifier is unique in live_matches, so each match appears once.
+    rows, err := s.db.Query(ctx, `SELECT match_identifier, last_updated
+        FROM live_matches
+        WHERE live_status = 'live'
+        ORDER BY last_updated ASC NULLS FIRST`)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    now := time.Now()
+    matches := []trackedMatch{}
+    for rows.Next() {
+        var m trackedMatch
+        if err := rows.Scan(&m.MatchID, &m.LastUpdated); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        if m.LastUpdated != nil {
+            age := int(now.Sub(*m.LastUpdated).Seconds())
+            m.AgeSeconds = &age
+        }
+        matches = append(matches, m)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, trackedMatchesResponse{Data: matches})
+}
+
+// liveBoardCard is one in-progress match with its latest prediction. The
+// prediction fields are null for live matches nobody predicted.
+type liveBoardCard struct {
+    MatchID         string     `json:"match_id"`
+    LiveScore       *string    `json:"live_score"`
+    LiveStatus      *string    `json:"live_status"`
+    LastUpdated     *time.Time `json:"last_updated"`
+    PredictionID    *int       `json:"prediction_id"`
+    Tournament      *string    `json:"tournament"`
+    Player1         *string    `json:"player1"`
+    Player2         *string    `json:"player2"`
+    PredictedWinner *string    `json:"predicted_winner"`
+    ConfidenceScore *int       `json:"confidence_score"`
+    OddsPlayer1     *float64   `json:"odds_player1"`
+    OddsPlayer2     *float64   `json:"odds_player2"`
+}
+
+type liveBoardResponse struct {
+    Data []liveBoardCard `json:"data"`
+}
+
+// handleLiveBoard returns one card per in-progress match with its most
+// recent prediction, most recently updated first. Live matches with no
+// prediction are left off the board.
+func (s *server) handleLiveBoard(w http.ResponseWriter, r *http.Request) {
+    cards, err := s.loadLiveCards(r.Context(), false)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    respondJSON(w, liveBoardResponse{Data: cards})
+}
+
+type scoreboardMeta struct {
+    AsOf time.Time `json:"as_of"`
+}
+
+type scoreboardResponse struct {
+    Data []liveBoardCard `json:"data"`
+    Meta scoreboardMeta  `json:"meta"`
+}
+
+// handleScoreboard is the kiosk view of every in-progress match, predicted
+// or not, most recently updated first. meta.as_of is taken before the
+// query, so nothing returned is newer than it.
+func (s *server) handleScoreboard(w http.ResponseWriter, r *http.Request) {
+    asOf := time.Now().UTC()
+    cards, err := s.loadLiveCards(r.Context(), true)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    respondJSON(w, scoreboardResponse{Data: cards, Meta: scoreboardMeta{AsOf: asOf}})
+}
+
+// loadLiveCards joins each in-progress live match to its latest prediction.
+// includeUnpredicted keeps live matches that have no prediction.
+func (s *server) loadLiveCards(ctx context.Context, includeUnpredicted bool) ([]liveBoardCard, error) {
+    join := "JOIN"
+    if includeUnpredicted {
+        join = "LEFT JOIN"
+    }
+    rows, err := s.db.Query(ctx, `SELECT
+        l.match_identifier, l.live_score, l.live_status, l.last_updated,
+        p.prediction_id, p.tournament, p.player1, p.player2,
+        p.predicted_winner, p.confidence_score, p.odds_player1, p.odds_player2
+        FROM live_matches l
+        `+join+` LATERAL (
+            SELECT DISTINCT ON (match_id) *
+            FROM predictions
+            WHERE match_id = l.match_identifier
+            ORDER BY match_id, created_at DESC, prediction_id DESC
+        ) p ON TRUE
+        WHERE l.live_status = 'live'
+        ORDER BY l.last_updated DESC NULLS LAST, l.match_identifier`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    cards := []liveBoardCard{}
+    for rows.Next() {
+        var c liveBoardCard
+        if err := rows.Scan(&c.MatchID, &c.LiveScore, &c.LiveStatus, &c.LastUpdated,
+            &c.PredictionID, &c.Tournament, &c.Player1, &c.Player2,
+            &c.PredictedWinner, &c.ConfidenceScore, &c.OddsPlayer1, &c.OddsPlayer2); err != nil {
+            return nil, err
+        }
+        c.OddsPlayer1 = roundPtr(c.OddsPlayer1, oddsDecimals)
+        c.OddsPlayer2 = roundPtr(c.OddsPlayer2, oddsDecimals)
+        cards = append(cards, c)
+    }
+    return cards, rows.Err()
+}
+
+// liveLeaderExpr makes a best-effort guess at who is ahead from
+// l.live_score. It is 1 when player1 leads, -1 when player2 leads and 0 when
+// level; NULL when the score can't be parsed or there is none. It is SQL so
+// filters on it apply before LIMIT/OFFSET and the count.
+//
+// Assumptions, matching what the live scraper writes:
+//   - sets are whitespace-separated "p1-p2" game counts, e.g. "6-4 3-2";
+//   - scores are always player1 first, in the predictions' player order;
+//   - a set is finished at 7 games, or at 6+ games with a two-game margin;
+//     anything else is the set in progress;
+//   - sets won decide the leader, and games in the last set, if it is still
+//     in progress, break ties.
+//
+// Point scores, tiebreak details and retirements aren't understood.
+const liveLeaderExpr = `(SELECT CASE
+        WHEN COUNT(*) = 0 OR NOT bool_and(sets.ok) THEN NULL
+        WHEN SUM(sets.won) <> 0 THEN sign(SUM(sets.won))
+        ELSE sign((array_agg(sets.open_diff ORDER BY sets.n DESC))[1])
+    END
+    FROM (
+        SELECT games.n, games.ok,
+            CASE WHEN games.ok AND (games.a = 7 OR games.b = 7 OR ((games.a >= 6 OR games.b >= 6) AND abs(games.a - games.b) >= 2))
+                THEN sign(games.a - games.b) ELSE 0 END AS won,
+            CASE WHEN games.ok AND NOT (games.a = 7 OR games.b = 7 OR ((games.a >= 6 OR games.b >= 6) AND abs(games.a - games.b) >= 2))
+                THEN games.a - games.b ELSE 0 END AS open_diff
+        FROM (
+            SELECT tok.n, tok.s ~ '^[0-9]{1,3}-[0-9]{1,3}$' AS ok,
+                CASE WHEN tok.s ~ '^[0-9]{1,3}-[0-9]{1,3}$' THEN split_part(tok.s, '-', 1)::int END AS a,
+                CASE WHEN tok.s ~ '^[0-9]{1,3}-[0-9]{1,3}$' THEN split_part(tok.s, '-', 2)::int END AS b
+            FROM regexp_split_to_table(btrim(l.live_score, E' \t\r\n'), '\s+') WITH ORDINALITY AS tok(s, n)
+        ) games
+    ) sets)`
+
+// liveAtRiskExpr keeps in-progress matches where the predicted winner is
+// behind. Scores liveLeaderExpr can't read are kept, so the filter degrades
+// to "all in-progress" rather than hiding rows.
+const liveAtRiskExpr = "(l.live_status = 'live' AND COALESCE((CASE WHEN p.predicted_winner = p.player1 THEN 1 ELSE -1 END) * " + liveLeaderExpr + " < 0, TRUE))"
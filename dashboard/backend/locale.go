@@ -0,0 +1,118 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// labelCatalog maps a locale ("fr", "pt-br") to display labels keyed by
+// the raw tournament or surface value stored in the database.
+type labelCatalog map[string]map[string]string
+
+// loadLabels reads LABELS_FILE, shaped as {"fr": {"Clay": "Terre battue"}}.
+// Locales are matched case-insensitively. No file means no localization.
+func loadLabels() (labelCatalog, error) {
+    path := os.Getenv("LABELS_FILE")
+    if path == "" {
+        return labelCatalog{}, nil
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var decoded map[string]map[string]string
+    if err := json.Unmarshal(data, &decoded); err != nil {
+        return nil, fmt.Errorf("parse labels: %w", err)
+    }
+    catalog := make(labelCatalog, len(decoded))
+    for locale, labels := range decoded {
+        catalog[strings.ToLower(locale)] = labels
+    }
+    return catalog, nil
+}
+
+// locale picks the catalog locale for r: the locale param if given,
+// otherwise the best Accept-Language match. A regional tag falls back to
+// its base language (fr-CA to fr). It returns "" when nothing matches.
+func (c labelCatalog) locale(r *http.Request) string {
+    if len(c) == 0 {
+        return ""
+    }
+    if v := strings.TrimSpace(paramLocale.get(r)); v != "" {
+        return c.match(v)
+    }
+
+    type weighted struct {
+        tag string
+        q   float64
+    }
+    var tags []weighted
+    for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+        tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+        q := 1.0
+        if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+            if f, err := strconv.ParseFloat(v, 64); err == nil {
+                q = f
+            }
+        }
+        if tag != "" && tag != "*" && q > 0 {
+            tags = append(tags, weighted{tag, q})
+        }
+    }
+    sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+    for _, t := range tags {
+        if locale := c.match(t.tag); locale != "" {
+            return locale
+        }
+    }
+    return ""
+}
+
+func (c labelCatalog) match(tag string) string {
+    tag = strings.ToLower(tag)
+    if _, ok := c[tag]; ok {
+        return tag
+    }
+    if base, _, found := strings.Cut(tag, "-"); found {
+        if _, ok := c[base]; ok {
+            return base
+        }
+    }
+    return ""
+}
+
+// label returns the display label for raw in locale, or raw itself when
+// there is no translation.
+func (c labelCatalog) label(locale, raw string) string {
+    if l, ok := c[locale][raw]; ok {
+        return l
+    }
+    return raw
+}
+
+// labelsFor maps each raw value to its label in locale.
+func (c labelCatalog) labelsFor(locale string, lists ...[]string) map[string]string {
+    labels := map[string]string{}
+    for _, values := range lists {
+        for _, raw := range values {
+            labels[raw] = c.label(locale, raw)
+        }
+    }
+    return labels
+}
+
+// localize fills the display labels on each row. Raw values are untouched
+// so they can still be sent back as filters.
+func (c labelCatalog) localize(locale string, rows []prediction) {
+    for i := range rows {
+        tournament := c.label(locale, rows[i].Tournament)
+        surface := c.label(locale, rows[i].Surface)
+        rows[i].TournamentLabel = &tournament
+        rows[i].SurfaceLabel = &surface
+    }
+}
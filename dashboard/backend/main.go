@@ -6,20 +6,34 @@ import (
     "errors"
     "fmt"
     "log"
+    "math"
     "net/http"
+    "net/url"
     "os"
+    "os/signal"
     "strconv"
     "strings"
+    "sync"
+    "syscall"
     "time"
+    _ "time/tzdata"
 
     "github.com/go-chi/chi/v5"
     "github.com/go-chi/cors"
+    "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgxpool"
-    "github.com/jackc/pgx/v5/pgconn"
+    "golang.org/x/sync/errgroup"
 )
 
 type server struct {
-    db *pgxpool.Pool
+    db           *pgxpool.Pool
+    // replica serves read-heavy stats, facets and exports. It is the same
+    // pool as db unless READ_REPLICA_URL is set.
+    replica      *pgxpool.Pool
+    presets      map[string]filterPreset
+    filtersCache *filtersCache
+    labels       labelCatalog
+    levels       []levelRule
 }
 
 type prediction struct {
@@ -56,18 +70,49 @@ type prediction struct {
     LiveScore                 *string    `json:"live_score,omitempty"`
     LiveStatus                *string    `json:"live_status,omitempty"`
     LastUpdated               *time.Time `json:"last_updated,omitempty"`
+    OpeningOdds               *float64   `json:"opening_odds,omitempty"`
+    MarketAgrees              *bool      `json:"market_agrees,omitempty"`
+    DaysUntilMatch            *int       `json:"days_until_match,omitempty"`
+    ExpectedValue             *float64   `json:"ev,omitempty"`
+    TournamentLabel           *string    `json:"tournament_label,omitempty"`
+    SurfaceLabel              *string    `json:"surface_label,omitempty"`
+    LiveStaleSeconds          *int       `json:"live_stale_seconds,omitempty"`
+    KellyFraction             *float64   `json:"kelly_fraction,omitempty"`
+    RecommendedStake          *float64   `json:"recommended_stake,omitempty"`
+    Surprise                  *int       `json:"surprise,omitempty"`
+}
+
+// Output precision for floats. The database keeps full precision; these only
+// apply when serializing so the UI doesn't show 0.6142857142857143.
+const (
+    oddsDecimals  = 2
+    ratioDecimals = 4
+)
+
+// roundForOutput rounds odds and accuracy fields for serialization. Nil
+// pointers are left nil so they still encode as null.
+func (p *prediction) roundForOutput() {
+    p.OddsPlayer1 = roundTo(p.OddsPlayer1, oddsDecimals)
+    p.OddsPlayer2 = roundTo(p.OddsPlayer2, oddsDecimals)
+    p.OpeningOdds = roundPtr(p.OpeningOdds, oddsDecimals)
+    p.SystemAccuracyAtPrediction = roundPtr(p.SystemAccuracyAtPrediction, ratioDecimals)
+    p.ExpectedValue = roundPtr(p.ExpectedValue, ratioDecimals)
 }
 
 type predictionsResponse struct {
-    Data []prediction      `json:"data"`
-    Meta responseMeta      `json:"meta"`
+    Data       predictionRows `json:"data"`
+    Meta       *responseMeta  `json:"meta,omitempty"`
+    // NextCursor is set here only without meta, which otherwise carries it.
+    NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 type responseMeta struct {
-    Total       int `json:"total"`
-    Page        int `json:"page"`
-    PageSize    int `json:"page_size"`
-    TotalPages  int `json:"total_pages"`
+    Total       int        `json:"total"`
+    Page        int        `json:"page"`
+    PageSize    int        `json:"page_size"`
+    TotalPages  int        `json:"total_pages"`
+    ServerTime  *time.Time `json:"server_time,omitempty"`
+    NextCursor  string     `json:"next_cursor,omitempty"`
 }
 
 func main() {
@@ -76,13 +121,37 @@ func main() {
         log.Fatal("DATABASE_URL env var is required")
     }
 
-    ctx := context.Background()
-    pool, err := pgxpool.New(ctx, dbURL)
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    pool, err := newPool(ctx, dbURL)
     if err != nil {
         log.Fatalf("failed to create pgx pool: %v", err)
     }
     defer pool.Close()
 
+    replica := pool
+    if replicaURL := os.Getenv("READ_REPLICA_URL"); replicaURL != "" {
+        replica, err = newPool(ctx, replicaURL)
+        if err != nil {
+            log.Fatalf("failed to create read replica pool: %v", err)
+        }
+        defer replica.Close()
+    }
+
+    if err := detectSchema(ctx, pool); err != nil {
+        log.Fatalf("failed to inspect schema: %v", err)
+    }
+    liveDataEnabled = schema.hasLiveMatches && envBool("LIVE_DATA_ENABLED", true)
+    if !liveDataEnabled {
+        if !schema.hasLiveMatches {
+            log.Printf("warning: live_matches table not found; live data is unavailable")
+        } else {
+            log.Printf("warning: LIVE_DATA_ENABLED=false; live data is unavailable")
+        }
+        predictionsFrom = predictionsFromNoLive
+    }
+
     port := os.Getenv("PORT")
     if port == "" {
         port = "3001"
@@ -91,126 +160,599 @@ func main() {
     r := chi.NewRouter()
     r.Use(cors.Handler(cors.Options{
         AllowedOrigins:   []string{"*"},
-        AllowedMethods:   []string{"GET", "OPTIONS"},
-        AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+        AllowedMethods:   []string{"GET", "HEAD", "POST", "OPTIONS"},
+        AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key", "X-Page-Size", "X-Nulls", "If-Modified-Since"},
+        ExposedHeaders:   []string{"X-Total-Count", "X-Result-Count", "Content-Disposition", "X-DB-Queries"},
         AllowCredentials: false,
         MaxAge:           300,
     }))
 
-    srv := &server{db: pool}
-    r.Get("/api/predictions", srv.handleListPredictions)
-    r.Get("/api/filters", srv.handleGetFilters)
-    r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
-        w.WriteHeader(http.StatusOK)
-        _, _ = w.Write([]byte("ok"))
-    })
+    // BASE_PATH mounts everything under a prefix (e.g. /tennis) for reverse
+    // proxies. HEALTH_AT_ROOT keeps /healthz unprefixed for probes.
+    basePath := normalizeBasePath(os.Getenv("BASE_PATH"))
+    healthAtRoot := envBool("HEALTH_AT_ROOT", false)
+    if envBool("LOG_FAILED_REQUESTS", true) {
+        r.Use(logFailures)
+    }
+    r.Use(compressResponses(basePath))
+    r.Use(cacheHeaders(basePath, cacheRules()))
+    // DEBUG_ENDPOINTS adds per-request diagnostics such as X-DB-Queries and
+    // the /api/debug routes.
+    debugEndpoints := envBool("DEBUG_ENDPOINTS", false)
+    if debugEndpoints {
+        r.Use(countQueries)
+    }
 
-    log.Printf("listening on :%s", port)
-    if err := http.ListenAndServe(":"+port, r); err != nil {
+    adminKey := os.Getenv("ADMIN_API_KEY")
+    if adminKey == "" {
+        log.Printf("ADMIN_API_KEY not set; admin endpoints are disabled")
+    }
+
+    liveJoinDays = envInt("LIVE_JOIN_DAYS", 0)
+    searchMaxLength = envInt("SEARCH_MAX_LENGTH", searchMaxLength)
+    paginationMeta = envBool("PAGINATION_META", true)
+    if n := envInt("MAX_PAGE_OFFSET", maxPageOffset); n > 0 {
+        maxPageOffset = n
+    } else {
+        log.Printf("MAX_PAGE_OFFSET must be positive; using %d", maxPageOffset)
+    }
+
+    presets, err := loadPresets()
+    if err != nil {
+        log.Fatalf("failed to load filter presets: %v", err)
+    }
+
+    labels, err := loadLabels()
+    if err != nil {
+        log.Fatalf("failed to load labels: %v", err)
+    }
+
+    levels, err := loadLevelRules()
+    if err != nil {
+        log.Fatalf("failed to load tournament levels: %v", err)
+    }
+
+    srv := &server{
+        db:           pool,
+        replica:      replica,
+        presets:      presets,
+        filtersCache: newFiltersCache(time.Duration(envInt("FILTERS_CACHE_TTL", 60)) * time.Second),
+        labels:       labels,
+        levels:       levels,
+    }
+    routes := func(r chi.Router) {
+        r.Use(srv.expandPresets)
+        r.Get("/api/predictions", srv.handleListPredictions)
+        r.Head("/api/predictions", srv.handleHeadPredictions)
+        r.Get("/api/predictions/results", srv.handleListResults)
+        // Always empty under UNIQUE(match_id); kept for databases that
+        // predate the constraint.
+        r.Get("/api/predictions/duplicates", srv.handleListDuplicates)
+        r.With(requireLiveData).Get("/api/predictions/conflicts", srv.handleListConflicts)
+        r.Get("/api/predictions/highlights", srv.handleHighlights)
+        r.Get("/api/predictions/upsets", srv.handleUpsets)
+        r.Get("/api/predictions/surprises", srv.handleSurprises)
+        r.Get("/api/predictions/staking", srv.handleStakingPlan)
+        r.Get("/api/predictions/validate", handleValidateFilters)
+        r.Get("/api/predictions/export", srv.handleExportPredictions)
+        r.Get("/api/predictions/by-match/{matchId}", srv.handlePredictionsByMatch)
+        r.With(requireJSON).Post("/api/predictions/by-matches", srv.handlePredictionsByMatches)
+        r.Get("/api/predictions/bucket/{bucket}", srv.handlePredictionsByBucket)
+        r.Get("/api/predictions/day/{date}", srv.handlePredictionsByDay)
+        r.Get("/api/predictions/{id}/neighbors", srv.handlePredictionNeighbors)
+        // One row and no changes under UNIQUE(match_id); see the handler.
+        r.Get("/api/predictions/{id}/history", srv.handlePredictionHistory)
+        r.Get("/api/filters", srv.handleGetFilters)
+        r.Get("/api/filters/meta", srv.handleGetFiltersMeta)
+        r.Get("/api/filters/{field}", srv.handleGetFilterValues)
+        r.Get("/api/dashboard", srv.handleDashboard)
+        r.Get("/api/surfaces", srv.handleSurfaces)
+        r.Get("/api/presets", srv.handleListPresets)
+        r.Get("/api/facets/all", srv.handleAllFacets)
+        r.Get("/api/facets/{name}", srv.handleFacet)
+        r.With(requireLiveData).Get("/api/live/tracked", srv.handleTrackedMatches)
+        r.With(requireLiveData).Get("/api/live/board", srv.handleLiveBoard)
+        r.With(requireLiveData).Get("/api/live/scoreboard", srv.handleScoreboard)
+        r.Get("/api/players/{name}/calibration", srv.handlePlayerCalibration)
+        r.Get("/api/stats/daily-recommendations", srv.handleDailyRecommendations)
+        r.Get("/api/stats/by-dow", srv.handleStatsByDayOfWeek)
+        r.Get("/api/stats/by-round", srv.handleStatsByRound)
+        r.Get("/api/stats/by-level", srv.handleStatsByLevel)
+        r.Get("/api/stats/cache", srv.handleCacheStats)
+        r.Get("/api/stats/vs-market", srv.handleStatsVsMarket)
+        r.Get("/api/stats/compare", srv.handleStatsCompare)
+        r.Get("/api/stats/position-bias", srv.handleStatsPositionBias)
+        r.Get("/api/stats/actions", srv.handleStatsActions)
+        r.Get("/api/stats/tournaments", srv.handleStatsTournaments)
+        r.Get("/api/stats/rolling", srv.handleStatsRolling)
+        r.Get("/api/stats/ev", srv.handleStatsEV)
+        r.Get("/api/stats/calendar", srv.handleStatsCalendar)
+        r.Get("/api/stats/calibration-over-time", srv.handleCalibrationOverTime)
+        r.Get("/api/stats/timeseries", srv.handleStatsTimeseries)
+        r.Get("/api/stats/bankroll", srv.handleStatsBankroll)
+        r.Get("/api/stats/value-bet-record", srv.handleStatsValueBetRecord)
+        r.Get("/api/stats/quality-vs-accuracy", srv.handleStatsQualityVsAccuracy)
+        if debugEndpoints {
+            r.Get("/api/debug/filters", handleDebugFilters)
+            // Flips can't find anything under UNIQUE(match_id), so it stays
+            // off the public API until it's clear it is still wanted.
+            r.Get("/api/predictions/flips", srv.handleListFlips)
+        }
+        r.Route("/api/admin", func(r chi.Router) {
+            r.Use(requireAPIKey(adminKey))
+            r.Get("/bucket-audit", srv.handleBucketAudit)
+            r.Get("/export/full", srv.handleExportFull)
+            r.With(requireJSON, requireLiveData).Post("/resolve/{matchId}", srv.handleResolveMatch)
+        })
+        if !healthAtRoot {
+            r.Get("/healthz", handleHealthz)
+            r.Get("/readyz", srv.handleReadyz)
+        }
+    }
+    if basePath == "" {
+        routes(r)
+    } else {
+        r.Route(basePath, routes)
+    }
+    if healthAtRoot {
+        r.Get("/healthz", handleHealthz)
+        r.Get("/readyz", srv.handleReadyz)
+    }
+
+    var background sync.WaitGroup
+    if interval := envInt("POOL_STATS_INTERVAL", 60); interval > 0 {
+        background.Add(1)
+        go func() {
+            defer background.Done()
+            logPoolStats(ctx, pool, time.Duration(interval)*time.Second)
+        }()
+    }
+
+    httpServer := &http.Server{Addr: ":" + port, Handler: r}
+    go func() {
+        <-ctx.Done()
+        shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        defer cancel()
+        if err := httpServer.Shutdown(shutdownCtx); err != nil {
+            log.Printf("shutdown error: %v", err)
+        }
+    }()
+
+    log.Printf("listening on :%s%s", port, basePath)
+    if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
         log.Fatalf("server error: %v", err)
     }
+    background.Wait()
+    log.Printf("server stopped")
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+    w.WriteHeader(http.StatusOK)
+    _, _ = w.Write([]byte("ok"))
+}
+
+// handleReadyz reports ready only when the primary pool, and the read
+// replica if one is configured, answer a ping.
+func (s *server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+    ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+    defer cancel()
+    if err := s.db.Ping(ctx); err != nil {
+        log.Printf("readyz: primary: %v", err)
+        http.Error(w, "primary unavailable", http.StatusServiceUnavailable)
+        return
+    }
+    if s.replica != s.db {
+        if err := s.replica.Ping(ctx); err != nil {
+            log.Printf("readyz: replica: %v", err)
+            http.Error(w, "replica unavailable", http.StatusServiceUnavailable)
+            return
+        }
+    }
+    w.WriteHeader(http.StatusOK)
+    _, _ = w.Write([]byte("ok"))
+}
+
+// newPool opens a pgx pool for connURL. DB_STATEMENT_TIMEOUT_MS makes
+// Postgres itself abort runaway queries, even after the client has gone
+// away; 0 keeps the server default.
+func newPool(ctx context.Context, connURL string) (*pgxpool.Pool, error) {
+    config, err := pgxpool.ParseConfig(connURL)
+    if err != nil {
+        return nil, err
+    }
+    if ms := envInt("DB_STATEMENT_TIMEOUT_MS", 0); ms > 0 {
+        config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.Itoa(ms)
+    }
+    config.ConnConfig.Tracer = queryCounter{}
+    return pgxpool.NewWithConfig(ctx, config)
+}
+
+// normalizeBasePath turns "tennis/", "/tennis" and "/tennis/" into "/tennis",
+// and "" or "/" into "" (no prefix).
+func normalizeBasePath(raw string) string {
+    trimmed := strings.Trim(strings.TrimSpace(raw), "/")
+    if trimmed == "" {
+        return ""
+    }
+    return "/" + trimmed
+}
+
+// logPoolStats logs connection pool usage every interval until ctx is done,
+// giving a cheap time series of pool pressure in the service logs.
+func logPoolStats(ctx context.Context, pool *pgxpool.Pool, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            stat := pool.Stat()
+            log.Printf("pool stats: acquired=%d idle=%d total=%d max=%d",
+                stat.AcquiredConns(), stat.IdleConns(), stat.TotalConns(), stat.MaxConns())
+        }
+    }
 }
 
 func (s *server) handleListPredictions(w http.ResponseWriter, r *http.Request) {
-    ctx := r.Context()
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    s.respondPredictionPage(w, r, filters)
+}
 
-    page := parseIntQuery(r, "page", 1)
-    if page < 1 {
-        page = 1
+// handlePredictionsByBucket is the list endpoint scoped to one confidence
+// bucket from the path, for the UI's per-bucket tabs.
+func (s *server) handlePredictionsByBucket(w http.ResponseWriter, r *http.Request) {
+    bucket := strings.ToLower(chi.URLParam(r, "bucket"))
+    if !isConfidenceBucket(bucket) {
+        respondError(w, http.StatusNotFound, fmt.Sprintf("unknown confidence bucket %q", bucket))
+        return
     }
-    pageSize := parseIntQuery(r, "pageSize", 25)
-    if pageSize < 1 {
-        pageSize = 25
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
     }
-    if pageSize > 1000 {
-        pageSize = 1000
+    filters.ConfidenceBucket = bucket
+    s.respondPredictionPage(w, r, filters)
+}
+
+// handlePredictionsByDay is the list endpoint scoped to one prediction_day
+// from the path (YYYY-MM-DD), for the calendar drill-down. It reuses the
+// dateFrom/dateTo bounds, so a prediction_day with a time part still lands
+// on its calendar day, and overrides either param if given.
+func (s *server) handlePredictionsByDay(w http.ResponseWriter, r *http.Request) {
+    day, err := time.Parse("2006-01-02", chi.URLParam(r, "date"))
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
     }
+    filters.DateFrom = &day
+    filters.DateTo = &day
+    s.respondPredictionPage(w, r, filters)
+}
+
+// respondPredictionPage writes one page of full predictions matching
+// filters, with the pagination taken from the request.
+func (s *server) respondPredictionPage(w http.ResponseWriter, r *http.Request, filters filterSet) {
+    ctx := r.Context()
+    // Taken before querying so a client polling with updatedSince=server_time
+    // can't miss rows written while this request ran.
+    serverTime := time.Now().UTC()
 
-    filters := collectFilters(r)
+    page, pageSize, err := parsePagination(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
     query, args := buildPredictionQuery(filters, page, pageSize)
     countQuery, countArgs := buildPredictionCountQuery(filters)
 
-    total, err := s.fetchTotal(ctx, countQuery, countArgs)
-    if err != nil {
+    // A poller that already has the newest rows gets 304 before the page
+    // and count queries run. Only conditional requests pay for the scan of
+    // the filtered set; the rest take Last-Modified from the page.
+    var lastModified *time.Time
+    checkedModified := false
+    if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+        modifiedQuery, modifiedArgs := buildLastModifiedQuery(filters)
+        lastModified, err = s.fetchLastModified(ctx, modifiedQuery, modifiedArgs)
+        if err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        checkedModified = true
+        if lastModified != nil && !lastModified.Truncate(time.Second).After(since) {
+            w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+            w.WriteHeader(http.StatusNotModified)
+            return
+        }
+    }
+
+    // The count and the page are independent, so run them side by side; an
+    // error in either cancels the other through the group context. Without
+    // meta the count is skipped entirely.
+    withMeta := wantPageMeta(r)
+    var total int
+    var results []prediction
+    g, gctx := errgroup.WithContext(ctx)
+    if withMeta {
+        g.Go(func() error {
+            var err error
+            total, err = s.fetchTotal(gctx, countQuery, countArgs)
+            return err
+        })
+    }
+    g.Go(func() error {
+        var err error
+        results, err = s.fetchPredictions(gctx, query, args)
+        return err
+    })
+    if err := g.Wait(); err != nil {
         httpError(w, err, http.StatusInternalServerError)
         return
     }
 
-    rows, err := s.db.Query(ctx, query, args...)
+    if !checkedModified {
+        lastModified = pageLastModified(results)
+    }
+
+    // A full page may have more after it.
+    var nextCursor string
+    if len(results) == pageSize && filters.SortBy != "random" {
+        nextCursor = newCursor(filters, results[len(results)-1]).encode()
+    }
+
+    if len(s.labels) > 0 {
+        w.Header().Add("Vary", "Accept-Language")
+    }
+    if locale := s.labels.locale(r); locale != "" {
+        s.labels.localize(locale, results)
+    }
+
+    if lastModified != nil {
+        w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+    }
+    if !withMeta {
+        respondJSON(w, predictionsResponse{Data: newPredictionRows(r, results), NextCursor: nextCursor})
+        return
+    }
+    meta := pageMeta(total, page, pageSize)
+    meta.ServerTime = &serverTime
+    meta.NextCursor = nextCursor
+    respondPage(w, meta, predictionsResponse{Data: newPredictionRows(r, results), Meta: &meta})
+}
+
+// handleHeadPredictions answers HEAD with the filtered total in
+// X-Total-Count. Only the count query runs and no body is written.
+// Content-Length is omitted: matching GET would mean building the page
+// just to measure it, and compression changes its size anyway.
+func (s *server) handleHeadPredictions(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
     if err != nil {
-        httpError(w, err, http.StatusInternalServerError)
+        w.WriteHeader(http.StatusBadRequest)
         return
     }
+    countQuery, countArgs := buildPredictionCountQuery(filters)
+    total, err := s.fetchTotal(r.Context(), countQuery, countArgs)
+    if err != nil {
+        log.Printf("error: %v", err)
+        w.WriteHeader(http.StatusInternalServerError)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("X-Total-Count", strconv.Itoa(total))
+    w.WriteHeader(http.StatusOK)
+}
+
+// fetchPredictions runs a query built by buildPredictionQuery and scans the
+// rows, merging the live actual_winner and rounding for output.
+func (s *server) fetchPredictions(ctx context.Context, query string, args []any) ([]prediction, error) {
+    rows, err := s.db.Query(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
     defer rows.Close()
 
-    var results []prediction
+    now := time.Now()
+    results := []prediction{}
     for rows.Next() {
-        var p prediction
-        var liveActualWinner *string // Separate variable for live_matches.actual_winner
-        err := rows.Scan(
-            &p.PredictionID,
-            &p.MatchID,
-            &p.PredictionDate,
-            &p.PredictionDay,
-            &p.Tournament,
-            &p.Surface,
-            &p.Player1,
-            &p.Player2,
-            &p.OddsPlayer1,
-            &p.OddsPlayer2,
-            &p.PredictedWinner,
-            &p.ConfidenceScore,
-            &p.Reasoning,
-            &p.RiskAssessment,
-            &p.ValueBet,
-            &p.RecommendedAction,
-            &p.DataQualityScore,
-            &p.LearningPhase,
-            &p.DaysOperated,
-            &p.SystemAccuracyAtPrediction,
-            &p.DataLimitations,
-            &p.Player1DataAvailable,
-            &p.Player2DataAvailable,
-            &p.H2HDataAvailable,
-            &p.SurfaceDataAvailable,
-            &p.SimilarMatchesCount,
-            &p.ActualWinner,
-            &p.PredictionCorrect,
-            &p.ConfidenceBucket,
-            &p.CreatedAt,
-            &p.LiveScore,
-            &p.LiveStatus,
-            &p.LastUpdated,
-            &liveActualWinner,
-        )
-        // Use live_matches.actual_winner if available, otherwise keep predictions.actual_winner
-        if liveActualWinner != nil && *liveActualWinner != "" && (p.ActualWinner == nil || *p.ActualWinner == "") {
-            p.ActualWinner = liveActualWinner
-        }
+        p, err := scanPrediction(rows, now)
         if err != nil {
-            httpError(w, err, http.StatusInternalServerError)
-            return
+            return nil, err
         }
         results = append(results, p)
     }
     if rows.Err() != nil {
-        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return nil, rows.Err()
+    }
+    return results, nil
+}
+
+// handlePredictionsByMatch returns every prediction for a scraper match_id,
+// newest first, with the live overlay joined.
+func (s *server) handlePredictionsByMatch(w http.ResponseWriter, r *http.Request) {
+    matchID, err := url.PathUnescape(chi.URLParam(r, "matchId"))
+    if err != nil || matchID == "" {
+        respondError(w, http.StatusBadRequest, "invalid match id")
         return
     }
 
-    totalPages := intDivCeil(total, pageSize)
+    base := strings.Builder{}
+    writePredictionSelect(&base, false)
+    base.WriteString(" WHERE p.match_id = $1 ORDER BY p.created_at DESC")
+
+    results, err := s.fetchPredictions(r.Context(), base.String(), []any{matchID})
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    if len(results) == 0 {
+        respondError(w, http.StatusNotFound, "no predictions for match")
+        return
+    }
 
     respondJSON(w, predictionsResponse{
-        Data: results,
-        Meta: responseMeta{
-            Total:      total,
-            Page:       page,
-            PageSize:   pageSize,
-            TotalPages: totalPages,
-        },
+        Data: newPredictionRows(r, results),
+        Meta: &responseMeta{Total: len(results), Page: 1, PageSize: len(results), TotalPages: 1},
     })
 }
 
+// maxBatchMatchIDs caps the match_ids one by-matches request may ask for.
+const maxBatchMatchIDs = 500
+
+type byMatchesRequest struct {
+    MatchIDs []string `json:"match_ids"`
+}
+
+type byMatchesResponse struct {
+    Data    map[string]predictionRows `json:"data"`
+    Missing []string                  `json:"missing"`
+}
+
+// handlePredictionsByMatches is the live poller's batch lookup: it takes
+// {"match_ids": [...]} and returns each match's predictions, newest first,
+// keyed by match_id. Ids with no predictions are listed in missing.
+func (s *server) handlePredictionsByMatches(w http.ResponseWriter, r *http.Request) {
+    var req byMatchesRequest
+    if err := decodeJSONBody(w, r, &req); err != nil {
+        respondError(w, err.status, err.msg)
+        return
+    }
+    if len(req.MatchIDs) == 0 {
+        respondError(w, http.StatusBadRequest, "match_ids must not be empty")
+        return
+    }
+    if len(req.MatchIDs) > maxBatchMatchIDs {
+        respondError(w, http.StatusBadRequest, fmt.Sprintf("match_ids may list at most %d ids", maxBatchMatchIDs))
+        return
+    }
+
+    base := strings.Builder{}
+    writePredictionSelect(&base, false)
+    base.WriteString(" WHERE p.match_id = ANY($1) ORDER BY p.match_id, p.created_at DESC")
+
+    results, err := s.fetchPredictions(r.Context(), base.String(), []any{req.MatchIDs})
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    grouped := map[string][]prediction{}
+    for _, p := range results {
+        grouped[p.MatchID] = append(grouped[p.MatchID], p)
+    }
+    resp := byMatchesResponse{Data: map[string]predictionRows{}, Missing: []string{}}
+    seen := map[string]bool{}
+    for _, id := range req.MatchIDs {
+        if seen[id] {
+            continue
+        }
+        seen[id] = true
+        if rows, ok := grouped[id]; ok {
+            resp.Data[id] = newPredictionRows(r, rows)
+        } else {
+            resp.Missing = append(resp.Missing, id)
+        }
+    }
+
+    respondJSON(w, resp)
+}
+
+// scanPrediction scans one row written by writePredictionSelect, merging
+// the live actual_winner and preparing the row for output.
+func scanPrediction(rows pgx.Rows, now time.Time) (prediction, error) {
+    var p prediction
+    var liveActualWinner *string // Separate variable for live_matches.actual_winner
+    err := rows.Scan(
+        &p.PredictionID,
+        &p.MatchID,
+        &p.PredictionDate,
+        &p.PredictionDay,
+        &p.Tournament,
+        &p.Surface,
+        &p.Player1,
+        &p.Player2,
+        &p.OddsPlayer1,
+        &p.OddsPlayer2,
+        &p.PredictedWinner,
+        &p.ConfidenceScore,
+        &p.Reasoning,
+        &p.RiskAssessment,
+        &p.ValueBet,
+        &p.RecommendedAction,
+        &p.DataQualityScore,
+        &p.LearningPhase,
+        &p.DaysOperated,
+        &p.SystemAccuracyAtPrediction,
+        &p.DataLimitations,
+        &p.Player1DataAvailable,
+        &p.Player2DataAvailable,
+        &p.H2HDataAvailable,
+        &p.SurfaceDataAvailable,
+        &p.SimilarMatchesCount,
+        &p.ActualWinner,
+        &p.PredictionCorrect,
+        &p.ConfidenceBucket,
+        &p.CreatedAt,
+        &p.LiveScore,
+        &p.LiveStatus,
+        &p.LastUpdated,
+        &liveActualWinner,
+        &p.MarketAgrees,
+        &p.OpeningOdds,
+    )
+    // Use live_matches.actual_winner if available, otherwise keep predictions.actual_winner
+    if liveActualWinner != nil && *liveActualWinner != "" && (p.ActualWinner == nil || *p.ActualWinner == "") {
+        p.ActualWinner = liveActualWinner
+    }
+    if err != nil {
+        return p, err
+    }
+    if ev, ok := expectedValue(p.ConfidenceScore, p.predictedOdds()); ok {
+        p.ExpectedValue = &ev
+    }
+    p.roundForOutput()
+    p.DaysUntilMatch = daysUntil(p.PredictionDay, now)
+    // How long an in-progress match's feed has been quiet, so stale feeds
+    // can be flagged.
+    if p.LiveStatus != nil && *p.LiveStatus == "live" && p.LastUpdated != nil {
+        stale := int(now.Sub(*p.LastUpdated).Seconds())
+        p.LiveStaleSeconds = &stale
+    }
+    return p, nil
+}
+
+// pageLastModified is the newest created_at or live last_updated among
+// rows, nil when there are none. It can be older than the filtered set's
+// newest change, which only costs a client a 304 it could have had.
+func pageLastModified(rows []prediction) *time.Time {
+    var newest *time.Time
+    for i := range rows {
+        for _, t := range []*time.Time{rows[i].CreatedAt, rows[i].LastUpdated} {
+            if t != nil && (newest == nil || t.After(*newest)) {
+                newest = t
+            }
+        }
+    }
+    return newest
+}
+
+// fetchLastModified runs a buildLastModifiedQuery query; nil means the set
+// is empty.
+func (s *server) fetchLastModified(ctx context.Context, query string, args []any) (*time.Time, error) {
+    var t *time.Time
+    err := s.db.QueryRow(ctx, query, args...).Scan(&t)
+    return t, err
+}
+
 func (s *server) fetchTotal(ctx context.Context, query string, args []any) (int, error) {
     row := s.db.QueryRow(ctx, query, args...)
     var total int
@@ -221,165 +763,629 @@ func (s *server) fetchTotal(ctx context.Context, query string, args []any) (int,
 }
 
 type filtersResponse struct {
-    Tournaments    []string `json:"tournaments"`
-    Surfaces       []string `json:"surfaces"`
-    LearningPhases []string `json:"learning_phases"`
+    Tournaments        []string `json:"tournaments"`
+    Surfaces           []string `json:"surfaces"`
+    LearningPhases     []string `json:"learning_phases"`
+    RecommendedActions []string `json:"recommended_actions"`
 }
 
-func (s *server) handleGetFilters(w http.ResponseWriter, r *http.Request) {
-    ctx := r.Context()
+type filterValuesResponse struct {
+    Data    []string `json:"data"`
+    HasMore bool     `json:"has_more"`
+}
 
-    // Get unique tournaments
-    tournamentsQuery := `SELECT DISTINCT tournament FROM predictions WHERE tournament IS NOT NULL AND tournament != '' ORDER BY tournament`
-    tournamentRows, err := s.db.Query(ctx, tournamentsQuery)
+// handleGetFilterValues pages through one list from /api/filters (served
+// from the same cache), with an optional case-insensitive q prefix.
+func (s *server) handleGetFilterValues(w http.ResponseWriter, r *http.Request) {
+    filters, err := s.filtersCache.get(r.Context(), s.loadFilters)
     if err != nil {
         httpError(w, err, http.StatusInternalServerError)
         return
     }
-    defer tournamentRows.Close()
-
-    var tournaments []string
-    for tournamentRows.Next() {
-        var tournament string
-        if err := tournamentRows.Scan(&tournament); err != nil {
-            httpError(w, err, http.StatusInternalServerError)
-            return
-        }
-        tournaments = append(tournaments, tournament)
+    var values []string
+    switch chi.URLParam(r, "field") {
+    case "tournaments":
+        values = filters.Tournaments
+    case "surfaces":
+        values = filters.Surfaces
+    case "learning_phases":
+        values = filters.LearningPhases
+    case "recommended_actions":
+        values = filters.RecommendedActions
+    default:
+        respondError(w, http.StatusNotFound, "unknown filter field")
+        return
     }
+    page, hasMore := pageValues(values, parseFacetPage(r))
+    respondJSON(w, filterValuesResponse{Data: page, HasMore: hasMore})
+}
 
-    // Get unique surfaces
-    surfacesQuery := `SELECT DISTINCT surface FROM predictions WHERE surface IS NOT NULL AND surface != '' ORDER BY surface`
-    surfaceRows, err := s.db.Query(ctx, surfacesQuery)
+// localizedFilters adds display labels for tournaments and surfaces in the
+// caller's locale. The lists themselves keep the raw values to filter by.
+type localizedFilters struct {
+    filtersResponse
+    Locale string            `json:"locale"`
+    Labels map[string]string `json:"labels"`
+}
+
+func (s *server) handleGetFilters(w http.ResponseWriter, r *http.Request) {
+    filters, err := s.filtersCache.get(r.Context(), s.loadFilters)
     if err != nil {
         httpError(w, err, http.StatusInternalServerError)
         return
     }
-    defer surfaceRows.Close()
+    if len(s.labels) > 0 {
+        w.Header().Add("Vary", "Accept-Language")
+    }
+    if locale := s.labels.locale(r); locale != "" {
+        respondJSON(w, localizedFilters{
+            filtersResponse: filters,
+            Locale:          locale,
+            Labels:          s.labels.labelsFor(locale, filters.Tournaments, filters.Surfaces),
+        })
+        return
+    }
+    respondJSON(w, filters)
+}
 
-    var surfaces []string
-    for surfaceRows.Next() {
-        var surface string
-        if err := surfaceRows.Scan(&surface); err != nil {
-            httpError(w, err, http.StatusInternalServerError)
-            return
-        }
-        surfaces = append(surfaces, surface)
+// loadFilters collects the distinct values offered by the filter panel.
+func (s *server) loadFilters(ctx context.Context) (filtersResponse, error) {
+    var resp filtersResponse
+    var err error
+
+    // Get unique tournaments
+    resp.Tournaments, err = s.queryStrings(ctx, `SELECT DISTINCT tournament FROM predictions WHERE tournament IS NOT NULL AND tournament != '' ORDER BY tournament`)
+    if err != nil {
+        return resp, err
+    }
+
+    // Get unique surfaces
+    resp.Surfaces, err = s.queryStrings(ctx, `SELECT DISTINCT surface FROM predictions WHERE surface IS NOT NULL AND surface != '' ORDER BY surface`)
+    if err != nil {
+        return resp, err
     }
 
     // Get unique learning phases
-    phasesQuery := `SELECT DISTINCT learning_phase FROM predictions WHERE learning_phase IS NOT NULL AND learning_phase != '' ORDER BY learning_phase`
-    phaseRows, err := s.db.Query(ctx, phasesQuery)
+    resp.LearningPhases, err = s.queryStrings(ctx, `SELECT DISTINCT learning_phase FROM predictions WHERE learning_phase IS NOT NULL AND learning_phase != '' ORDER BY learning_phase`)
+    if err != nil {
+        return resp, err
+    }
+
+    // Get unique recommended actions
+    resp.RecommendedActions, err = s.queryStrings(ctx, `SELECT DISTINCT recommended_action FROM predictions WHERE recommended_action IS NOT NULL AND recommended_action != '' ORDER BY recommended_action`)
+    if err != nil {
+        return resp, err
+    }
+
+    return resp, nil
+}
+
+// valueRange is the observed min and max of a numeric column, both null
+// when there is no data.
+type valueRange struct {
+    Min *float64 `json:"min"`
+    Max *float64 `json:"max"`
+}
+
+type filtersMetaResponse struct {
+    filtersResponse
+    Confidence  valueRange `json:"confidence"`
+    Odds        valueRange `json:"odds"`
+    DataQuality valueRange `json:"data_quality"`
+}
+
+// handleGetFiltersMeta returns the categorical filter values alongside the
+// bounds of the numeric ones as they occur in the data, so sliders can be
+// scaled to real values.
+func (s *server) handleGetFiltersMeta(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+    values, err := s.filtersCache.get(ctx, s.loadFilters)
     if err != nil {
         httpError(w, err, http.StatusInternalServerError)
         return
     }
-    defer phaseRows.Close()
 
-    var phases []string
-    for phaseRows.Next() {
-        var phase string
-        if err := phaseRows.Scan(&phase); err != nil {
-            httpError(w, err, http.StatusInternalServerError)
-            return
+    resp := filtersMetaResponse{filtersResponse: values}
+    err = s.replica.QueryRow(ctx, `SELECT
+        MIN(confidence_score)::float8, MAX(confidence_score)::float8,
+        LEAST(MIN(odds_player1), MIN(odds_player2))::float8, GREATEST(MAX(odds_player1), MAX(odds_player2))::float8,
+        MIN(data_quality_score)::float8, MAX(data_quality_score)::float8
+        FROM predictions`).Scan(
+        &resp.Confidence.Min, &resp.Confidence.Max,
+        &resp.Odds.Min, &resp.Odds.Max,
+        &resp.DataQuality.Min, &resp.DataQuality.Max)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    resp.Odds.Min = roundPtr(resp.Odds.Min, oddsDecimals)
+    resp.Odds.Max = roundPtr(resp.Odds.Max, oddsDecimals)
+
+    respondJSON(w, resp)
+}
+
+// queryStrings runs a single-column query on the replica and collects the
+// values.
+func (s *server) queryStrings(ctx context.Context, query string, args ...any) ([]string, error) {
+    rows, err := s.replica.Query(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    values := []string{}
+    for rows.Next() {
+        var v string
+        if err := rows.Scan(&v); err != nil {
+            return nil, err
         }
-        phases = append(phases, phase)
+        values = append(values, v)
     }
+    return values, rows.Err()
+}
 
-    respondJSON(w, filtersResponse{
-        Tournaments:    tournaments,
-        Surfaces:       surfaces,
-        LearningPhases: phases,
+// predictionsFrom is the FROM clause shared by every predictions query; the
+// live overlay is always joined so filters can reference l.* columns. It
+// becomes predictionsFromNoLive when live data is disabled.
+var predictionsFrom = "FROM predictions p LEFT JOIN live_matches l ON l.match_identifier = p.match_id"
+
+// predictionsFromNoLive stands in an empty row set with live_matches'
+// columns for l, so the same queries run without the live feed.
+const predictionsFromNoLive = `FROM predictions p LEFT JOIN (SELECT
+        NULL::varchar AS match_identifier,
+        NULL::varchar AS live_score,
+        NULL::varchar AS live_status,
+        NULL::varchar AS actual_winner,
+        NULL::timestamptz AS last_updated,
+        NULL::timestamptz AS created_at
+    ) l ON FALSE`
+
+// liveDataEnabled is false when live_matches is missing or
+// LIVE_DATA_ENABLED=false; predictions are then served without live data
+// and the live endpoints answer 503.
+var liveDataEnabled = true
+
+// requireLiveData answers 503 on routes that depend on the live feed when
+// live data is disabled, rather than letting them report an empty result.
+func requireLiveData(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !liveDataEnabled {
+            respondError(w, http.StatusServiceUnavailable, "live data is unavailable")
+            return
+        }
+        next.ServeHTTP(w, r)
     })
 }
 
+// liveJoinDays, from LIVE_JOIN_DAYS, limits how far back the list and
+// export queries join live rows when no date filter narrows them. 0 joins
+// all history.
+var liveJoinDays int
+
+// scopedPredictionsFrom is predictionsFrom with the live join limited to
+// rows updated in the last liveJoinDays, for unbounded list queries. Live
+// rows are still joined for any prediction without its own actual_winner,
+// so the fallback result and correctness are unchanged; only the live
+// score/status of older resolved rows is left out.
+func scopedPredictionsFrom(filters filterSet) string {
+    bounded := filters.DateFrom != nil || filters.DateTo != nil || filters.WithinDays != nil ||
+        filters.ResolvedFrom != nil || filters.ResolvedTo != nil || filters.UpdatedSince != nil
+    if liveJoinDays <= 0 || bounded || filters.LiveAtRisk || filters.LiveStaleMinutes != nil {
+        return predictionsFrom
+    }
+    return predictionsFrom + fmt.Sprintf(
+        " AND (l.last_updated >= NOW() - make_interval(days => %d) OR NULLIF(p.actual_winner, '') IS NULL)", liveJoinDays)
+}
+
+// actualWinnerExpr prefers the stored result and falls back to the live feed,
+// mirroring how handleListPredictions merges the two.
+const actualWinnerExpr = "COALESCE(NULLIF(p.actual_winner, ''), NULLIF(l.actual_winner, ''))"
+
+// correctExpr grades a resolved prediction, preferring the stored flag and
+// comparing against actualWinnerExpr when it hasn't been set yet.
+const correctExpr = "COALESCE(p.prediction_correct, p.predicted_winner = " + actualWinnerExpr + ")"
+
+// predictedOddsExpr is the decimal odds of the player the model picked.
+const predictedOddsExpr = "CASE WHEN p.predicted_winner = p.player1 THEN p.odds_player1 ELSE p.odds_player2 END"
+
+// predictedOdds mirrors predictedOddsExpr for a scanned row.
+func (p prediction) predictedOdds() float64 {
+    if p.PredictedWinner == p.Player1 {
+        return p.OddsPlayer1
+    }
+    return p.OddsPlayer2
+}
+
+// marketAgreesExpr is true when the model picked the bookmakers' favorite
+// (strictly shorter odds), a closing-line-value proxy until odds history
+// is available.
+const marketAgreesExpr = "(CASE WHEN p.predicted_winner = p.player1 THEN p.odds_player1 < p.odds_player2 ELSE p.odds_player2 < p.odds_player1 END)"
+
 type filterSet struct {
     Search           string
     Tournament       string
+    TournamentLike   string
     Surface          string
     LearningPhase    string
-    RecommendedAction string
+    ConfidenceBucket string
+    RecommendedActions []string
+    ExcludeTournaments []string
+    ExcludeSurfaces  []string
     PredictionCorrect *bool
+    PredictionUnresolved bool
     ValueBet         *bool
+    MarketAgrees     *bool
+    HasReasoning     *bool
+    HasRiskAssessment *bool
+    InvalidOdds      *bool
     MinConfidence    *int
     MaxConfidence    *int
+    MinConfidenceDistance *int
+    MinOddsSpread    *float64
+    MaxOddsSpread    *float64
     DateFrom         *time.Time
     DateTo           *time.Time
+    WithinDays       *int
+    UpdatedSince     *time.Time
+    MinLeadTimeHours *int
+    LiveStaleMinutes *int
+    ResolvedFrom     *time.Time
+    ResolvedTo       *time.Time
     SortBy           string
     SortDir          string
+    Seed             string
+    Timezone         string
+    ResolvedOnly     bool
+    IncludeOddsHistory bool
+    LiveAtRisk       bool
+    BucketMismatch   bool
+    IncludeArchived  bool
+    Cursor           *pageCursor
+}
+
+// searchMaxLength, from SEARCH_MAX_LENGTH, caps the search term in
+// characters so a huge pattern can't make the LIKE scan crawl.
+var searchMaxLength = 100
+
+// limitSearch trims the search term and cuts it to searchMaxLength. A term
+// that is blank after trimming comes back empty, which applies no filter.
+func limitSearch(term string) string {
+    term = strings.TrimSpace(term)
+    if runes := []rune(term); searchMaxLength > 0 && len(runes) > searchMaxLength {
+        term = strings.TrimSpace(string(runes[:searchMaxLength]))
+    }
+    return term
 }
 
-func collectFilters(r *http.Request) filterSet {
-    search := strings.TrimSpace(r.URL.Query().Get("search"))
-    tournament := strings.TrimSpace(r.URL.Query().Get("tournament"))
-    surface := strings.TrimSpace(r.URL.Query().Get("surface"))
-    learningPhase := strings.TrimSpace(r.URL.Query().Get("learningPhase"))
-    recommendedAction := strings.TrimSpace(r.URL.Query().Get("recommendedAction"))
+// likeEscaper makes LIKE metacharacters match literally under the default
+// backslash escape.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// invalidOddsExpr is true for rows whose odds can't be decimal odds (they
+// must be over 1) and so break implied-probability and EV figures. The
+// columns are NOT NULL today; NULL is checked for older data.
+const invalidOddsExpr = "(p.odds_player1 IS NULL OR p.odds_player2 IS NULL OR p.odds_player1 <= 1 OR p.odds_player2 <= 1)"
+
+// presenceClause tests whether a text column has a non-empty value.
+func presenceClause(column string, present bool) string {
+    if present {
+        return "NULLIF(" + column + ", '') IS NOT NULL"
+    }
+    return "NULLIF(" + column + ", '') IS NULL"
+}
 
+// collectFilters parses the filter query params shared by the list and
+// stats endpoints. Malformed strict params and cross-field conflicts are
+// returned together as filterErrors; lenient params are ignored when invalid.
+func collectFilters(r *http.Request) (filterSet, error) {
+    var problems filterErrors
+
+    search := limitSearch(paramSearch.get(r))
+    // tournament matches one event exactly; tournamentLike is a
+    // case-insensitive substring ("ATP 250") and the two can be combined.
+    tournament := strings.TrimSpace(paramTournament.get(r))
+    tournamentLike := strings.TrimSpace(paramTournamentLike.get(r))
+    surface := strings.TrimSpace(paramSurface.get(r))
+    learningPhase := strings.TrimSpace(paramLearningPhase.get(r))
+    recommendedActions := splitCSV(paramRecommendedAction.get(r))
+    excludeTournaments := splitCSV(paramExcludeTournament.get(r))
+    excludeSurfaces := splitCSV(paramExcludeSurface.get(r))
+
+    // predictionCorrect is tri-state: true/false match resolved outcomes and
+    // unknown/null matches predictions that haven't been graded yet.
     var predictionCorrect *bool
-    if v := strings.TrimSpace(r.URL.Query().Get("predictionCorrect")); v != "" {
-        if b, err := strconv.ParseBool(v); err == nil {
-            predictionCorrect = &b
+    predictionUnresolved := false
+    if v := strings.TrimSpace(paramPredictionCorrect.get(r)); v != "" {
+        switch strings.ToLower(v) {
+        case "unknown", "null":
+            predictionUnresolved = true
+        default:
+            if b, err := strconv.ParseBool(v); err == nil {
+                predictionCorrect = &b
+            }
         }
     }
 
     var valueBet *bool
-    if v := strings.TrimSpace(r.URL.Query().Get("valueBet")); v != "" {
+    if v := strings.TrimSpace(paramValueBet.get(r)); v != "" {
         if b, err := strconv.ParseBool(v); err == nil {
             valueBet = &b
         }
     }
 
-    var minConfidence *int
-    if v := strings.TrimSpace(r.URL.Query().Get("minConfidence")); v != "" {
-        if n, err := strconv.Atoi(v); err == nil {
-            minConfidence = &n
+    var marketAgrees *bool
+    if v := strings.TrimSpace(paramMarketAgrees.get(r)); v != "" {
+        if b, err := strconv.ParseBool(v); err == nil {
+            marketAgrees = &b
         }
     }
 
-    var maxConfidence *int
-    if v := strings.TrimSpace(r.URL.Query().Get("maxConfidence")); v != "" {
-        if n, err := strconv.Atoi(v); err == nil {
-            maxConfidence = &n
+    var invalidOdds *bool
+    if v := strings.TrimSpace(paramInvalidOdds.get(r)); v != "" {
+        if b, err := strconv.ParseBool(v); err == nil {
+            invalidOdds = &b
+        }
+    }
+
+    // hasReasoning and hasRiskAssessment treat empty text the same as NULL.
+    var hasReasoning, hasRiskAssessment *bool
+    if v := strings.TrimSpace(paramHasReasoning.get(r)); v != "" {
+        if b, err := strconv.ParseBool(v); err == nil {
+            hasReasoning = &b
+        }
+    }
+    if v := strings.TrimSpace(paramHasRiskAssessment.get(r)); v != "" {
+        if b, err := strconv.ParseBool(v); err == nil {
+            hasRiskAssessment = &b
+        }
+    }
+
+    minConfidence, err := parseConfidenceParam(r, paramMinConfidence)
+    if err != nil {
+        problems = append(problems, err.Error())
+    }
+
+    maxConfidence, err := parseConfidenceParam(r, paramMaxConfidence)
+    if err != nil {
+        problems = append(problems, err.Error())
+    }
+
+    // minConfidenceDistance finds bold calls in either direction: how far the
+    // score sits from a 50/50 call.
+    var minConfidenceDistance *int
+    if v := strings.TrimSpace(paramMinConfidenceDistance.get(r)); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 0 {
+            problems = append(problems, "minConfidenceDistance must be a non-negative integer")
+        } else {
+            minConfidenceDistance = &n
+        }
+    }
+
+    var minOddsSpread *float64
+    if v := strings.TrimSpace(paramMinOddsSpread.get(r)); v != "" {
+        if f, err := strconv.ParseFloat(v, 64); err == nil {
+            minOddsSpread = &f
+        }
+    }
+
+    var maxOddsSpread *float64
+    if v := strings.TrimSpace(paramMaxOddsSpread.get(r)); v != "" {
+        if f, err := strconv.ParseFloat(v, 64); err == nil {
+            maxOddsSpread = &f
         }
     }
 
     var dateFrom *time.Time
-    if v := strings.TrimSpace(r.URL.Query().Get("dateFrom")); v != "" {
+    if v := strings.TrimSpace(paramDateFrom.get(r)); v != "" {
         if t, err := time.Parse("2006-01-02", v); err == nil {
             dateFrom = &t
         }
     }
 
     var dateTo *time.Time
-    if v := strings.TrimSpace(r.URL.Query().Get("dateTo")); v != "" {
+    if v := strings.TrimSpace(paramDateTo.get(r)); v != "" {
         if t, err := time.Parse("2006-01-02", v); err == nil {
             dateTo = &t
         }
     }
 
-    sortBy := sanitizeSortBy(r.URL.Query().Get("sortBy"))
-    sortDir := sanitizeSortDir(r.URL.Query().Get("sortDir"))
+    var withinDays *int
+    if v := strings.TrimSpace(paramWithinDays.get(r)); v != "" {
+        if n, err := strconv.Atoi(v); err == nil {
+            withinDays = &n
+        }
+    }
+
+    var updatedSince *time.Time
+    if v := strings.TrimSpace(paramUpdatedSince.get(r)); v != "" {
+        t, err := time.Parse(time.RFC3339, v)
+        if err != nil {
+            problems = append(problems, "updatedSince must be an RFC3339 timestamp")
+        } else {
+            updatedSince = &t
+        }
+    }
+
+    // minLeadTimeHours keeps predictions made at least N hours before their
+    // match day began, for integrity checks against late predictions.
+    var minLeadTimeHours *int
+    if v := strings.TrimSpace(paramMinLeadTimeHours.get(r)); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 0 {
+            problems = append(problems, "minLeadTimeHours must be a non-negative integer")
+        } else {
+            minLeadTimeHours = &n
+        }
+    }
 
-    return filterSet{
+    // liveStaleMinutes keeps in-progress matches whose live feed hasn't
+    // updated for at least N minutes.
+    var liveStaleMinutes *int
+    if v := strings.TrimSpace(paramLiveStaleMinutes.get(r)); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 {
+            problems = append(problems, "liveStaleMinutes must be a positive integer")
+        } else {
+            liveStaleMinutes = &n
+        }
+    }
+
+    var resolvedFrom *time.Time
+    if v := strings.TrimSpace(paramResolvedFrom.get(r)); v != "" {
+        if t, err := time.Parse("2006-01-02", v); err == nil {
+            resolvedFrom = &t
+        }
+    }
+
+    var resolvedTo *time.Time
+    if v := strings.TrimSpace(paramResolvedTo.get(r)); v != "" {
+        if t, err := time.Parse("2006-01-02", v); err == nil {
+            resolvedTo = &t
+        }
+    }
+
+    includeOddsHistory := false
+    if v := strings.TrimSpace(paramIncludeOddsHistory.get(r)); v != "" {
+        if b, err := strconv.ParseBool(v); err == nil {
+            includeOddsHistory = b
+        }
+    }
+
+    // tz is an IANA zone name used for "today" and timestamp date bounds.
+    timezone := strings.TrimSpace(paramTz.get(r))
+    if timezone == "" {
+        timezone = "UTC"
+    }
+    if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+        problems = append(problems, fmt.Sprintf("invalid tz %q", timezone))
+    }
+
+    liveAtRisk := false
+    if v := strings.TrimSpace(paramLiveAtRisk.get(r)); v != "" {
+        if b, err := strconv.ParseBool(v); err == nil {
+            liveAtRisk = b
+        }
+    }
+
+    // bucketMismatch keeps rows whose stored confidence_bucket disagrees
+    // with the canonical one, the rows /api/admin/bucket-audit counts.
+    bucketMismatch := false
+    if v := strings.TrimSpace(paramBucketMismatch.get(r)); v != "" {
+        if b, err := strconv.ParseBool(v); err == nil {
+            bucketMismatch = b
+        }
+    }
+
+    includeArchived := false
+    if v := strings.TrimSpace(paramIncludeArchived.get(r)); v != "" {
+        if b, err := strconv.ParseBool(v); err == nil {
+            includeArchived = b
+        }
+    }
+
+    sortBy := sanitizeSortBy(paramSortBy.get(r))
+    sortDir := sanitizeSortDir(paramSortDir.get(r))
+    seed := strings.TrimSpace(paramSeed.get(r))
+
+    // A cursor carries the sort it was issued for. Sort params may repeat it
+    // but not change it, since the cursor's position is only meaningful in
+    // that order.
+    var cursor *pageCursor
+    if v := strings.TrimSpace(paramCursor.get(r)); v != "" {
+        c, err := decodeCursor(v)
+        if err != nil {
+            problems = append(problems, "invalid cursor")
+        } else {
+            if sortBy != "" && sortBy != c.SortBy {
+                problems = append(problems, fmt.Sprintf("sortBy %q conflicts with the cursor's sort %q", sortBy, c.SortBy))
+            }
+            if sortDir != "" && sortDir != c.SortDir {
+                problems = append(problems, fmt.Sprintf("sortDir %q conflicts with the cursor's direction %q", sortDir, c.SortDir))
+            }
+            sortBy, sortDir = c.SortBy, c.SortDir
+            cursor = &c
+        }
+    }
+
+    filters := filterSet{
         Search:            search,
         Tournament:        tournament,
+        TournamentLike:    tournamentLike,
         Surface:           surface,
         LearningPhase:     learningPhase,
-        RecommendedAction: recommendedAction,
+        RecommendedActions: recommendedActions,
+        ExcludeTournaments: excludeTournaments,
+        ExcludeSurfaces:   excludeSurfaces,
         PredictionCorrect: predictionCorrect,
+        PredictionUnresolved: predictionUnresolved,
         ValueBet:          valueBet,
+        MarketAgrees:      marketAgrees,
+        HasReasoning:      hasReasoning,
+        HasRiskAssessment: hasRiskAssessment,
+        InvalidOdds:       invalidOdds,
         MinConfidence:     minConfidence,
         MaxConfidence:     maxConfidence,
+        MinConfidenceDistance: minConfidenceDistance,
+        MinOddsSpread:     minOddsSpread,
+        MaxOddsSpread:     maxOddsSpread,
         DateFrom:          dateFrom,
         DateTo:            dateTo,
+        WithinDays:        withinDays,
+        UpdatedSince:      updatedSince,
+        MinLeadTimeHours:  minLeadTimeHours,
+        LiveStaleMinutes:  liveStaleMinutes,
+        ResolvedFrom:      resolvedFrom,
+        ResolvedTo:        resolvedTo,
         SortBy:            sortBy,
         SortDir:           sortDir,
+        Seed:              seed,
+        Timezone:          timezone,
+        IncludeOddsHistory: includeOddsHistory,
+        LiveAtRisk:        liveAtRisk,
+        BucketMismatch:    bucketMismatch,
+        IncludeArchived:   includeArchived,
+        Cursor:            cursor,
+    }
+
+    if unknown := unknownQueryParams(r); len(unknown) > 0 {
+        problems = append(problems, fmt.Sprintf("unknown query params: %s", strings.Join(unknown, ", ")))
     }
+    problems = append(problems, validateFilters(filters)...)
+    if len(problems) > 0 {
+        return filters, problems
+    }
+    return filters, nil
 }
 
 func buildPredictionQuery(filters filterSet, page, pageSize int) (string, []any) {
     base := strings.Builder{}
+    writePredictionSelectFrom(&base, filters.IncludeOddsHistory, scopedPredictionsFrom(filters))
+
+    clauses, args := buildWhereClauses(filters)
+    // A cursor replaces the offset: it resumes after the last row it saw.
+    if filters.Cursor != nil {
+        var clause string
+        clause, args = filters.Cursor.clause(args)
+        clauses = append(clauses, clause)
+        page = 1
+    }
+    writeWhere(&base, clauses)
+
+    args = writeOrderAndPage(&base, filters, args, page, pageSize)
+
+    return base.String(), args
+}
+
+// writePredictionSelect writes the SELECT ... FROM for full prediction rows,
+// in the column order fetchPredictions scans.
+func writePredictionSelect(base *strings.Builder, includeOddsHistory bool) {
+    writePredictionSelectFrom(base, includeOddsHistory, predictionsFrom)
+}
+
+// writePredictionSelectFrom is writePredictionSelect with a custom FROM,
+// such as scopedPredictionsFrom.
+func writePredictionSelectFrom(base *strings.Builder, includeOddsHistory bool, from string) {
     base.WriteString(`SELECT
         p.prediction_id,
         p.match_id,
@@ -414,53 +1420,112 @@ func buildPredictionQuery(filters filterSet, page, pageSize int) (string, []any)
         l.live_score,
         l.live_status,
         l.last_updated,
-        l.actual_winner
-        FROM predictions p
-        LEFT JOIN live_matches l ON l.match_identifier = p.match_id`)
+        l.actual_winner,
+        ` + marketAgreesExpr + `,`)
+
+    // The odds history join is opt-in so ordinary list requests don't pay
+    // for it; without it the column is a typed NULL to keep the row shape.
+    if includeOddsHistory {
+        base.WriteString(`
+        CASE WHEN p.predicted_winner = p.player1 THEN oh.opening_odds_player1 ELSE oh.opening_odds_player2 END
+        ` + from + `
+        LEFT JOIN odds_history oh ON oh.match_id = p.match_id`)
+    } else {
+        base.WriteString(`
+        NULL::numeric
+        ` + from)
+    }
+}
 
-    clauses, args := buildWhereClauses(filters)
+// writeWhere appends a WHERE clause joining clauses with AND, if there are any.
+func writeWhere(base *strings.Builder, clauses []string) {
     if len(clauses) > 0 {
         base.WriteString(" WHERE ")
         base.WriteString(strings.Join(clauses, " AND "))
     }
+}
+
+// writeOrderAndPage appends the ORDER BY and LIMIT/OFFSET for the requested
+// sort and page to base, returning args extended with any new placeholders.
+func writeOrderAndPage(base *strings.Builder, filters filterSet, args []any, page, pageSize int) []any {
+    args = writeOrderBy(base, filters, args)
 
+    placeholder := len(args) + 1
+    base.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", placeholder, placeholder+1))
+
+    limit := pageSize
+    offset := (page - 1) * pageSize
+    return append(args, limit, offset)
+}
+
+// writeOrderBy appends the ORDER BY for the requested sort to base.
+func writeOrderBy(base *strings.Builder, filters filterSet, args []any) []any {
     orderBy := filters.SortBy
     if orderBy == "" {
         orderBy = "prediction_day"
     }
     
-    // Handle special sorting for predicted odds (calculated field)
-    if orderBy == "predicted_odds" {
-        orderBy = "CASE WHEN predicted_winner = player1 THEN odds_player1 ELSE odds_player2 END"
+    switch orderBy {
+    case "predicted_odds":
+        // Computed, so cast explicitly: if an odds column were ever stored as
+        // text the sort would otherwise turn lexical ("10.5" < "2.1"). The
+        // plain column sorts are DATE, TIMESTAMPTZ, INTEGER and NUMERIC in
+        // schema.sql and are left uncast so their indexes stay usable.
+        orderBy = "(" + predictedOddsExpr + ")::numeric"
+    case "random":
+        // Random sampling. Without a seed the order changes on every request,
+        // so paging through it can repeat or skip rows. With a seed the order
+        // is a stable hash of prediction_id, which keeps pagination consistent.
+        if filters.Seed != "" {
+            args = append(args, filters.Seed)
+            orderBy = fmt.Sprintf("md5(p.prediction_id::text || $%d)", len(args))
+        } else {
+            orderBy = "RANDOM()"
+        }
+    default:
+        // Qualified so the column stays unambiguous against live_matches.
+        orderBy = "p." + orderBy
     }
     
-    dir := filters.SortDir
-    if dir == "" {
-        dir = "DESC"
-    }
+    dir := sortDirOrDefault(filters.SortDir)
     base.WriteString(" ORDER BY ")
     base.WriteString(orderBy)
     base.WriteRune(' ')
     base.WriteString(dir)
+    // prediction_id breaks ties so rows with equal sort keys keep a fixed
+    // position across pages; cursors rely on it.
+    if orderBy != "RANDOM()" {
+        base.WriteString(", p.prediction_id ")
+        base.WriteString(dir)
+    }
+    return args
+}
 
-    placeholder := len(args) + 1
-    base.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", placeholder, placeholder+1))
-
-    limit := pageSize
-    offset := (page - 1) * pageSize
-    args = append(args, limit, offset)
+// sortDirOrDefault returns the sanitized sort direction, DESC when unset.
+func sortDirOrDefault(dir string) string {
+    if dir == "" {
+        return "DESC"
+    }
+    return dir
+}
 
+func buildPredictionCountQuery(filters filterSet) (string, []any) {
+    base := strings.Builder{}
+    base.WriteString("SELECT COUNT(*) " + scopedPredictionsFrom(filters))
+    clauses, args := buildWhereClauses(filters)
+    writeWhere(&base, clauses)
     return base.String(), args
 }
 
-func buildPredictionCountQuery(filters filterSet) (string, []any) {
+// buildLastModifiedQuery finds the newest created_at or live last_updated
+// across the filtered set, for Last-Modified. Changes that touch neither,
+// such as rows leaving the set or a resolution written straight to
+// predictions, don't move it.
+func buildLastModifiedQuery(filters filterSet) (string, []any) {
     base := strings.Builder{}
-    base.WriteString("SELECT COUNT(*) FROM predictions p LEFT JOIN live_matches l ON l.match_identifier = p.match_id")
+    base.WriteString("SELECT MAX(GREATEST(p.created_at, l.last_updated)) " + scopedPredictionsFrom(filters))
     clauses, args := buildWhereClauses(filters)
-    if len(clauses) > 0 {
-        base.WriteString(" WHERE ")
-        base.WriteString(strings.Join(clauses, " AND "))
-    }
+    writeWhere(&base, clauses)
     return base.String(), args
 }
 
@@ -473,15 +1538,29 @@ func buildWhereClauses(filters filterSet) ([]string, []any) {
         args = append(args, value)
     }
 
+    // Archived rows are soft-deleted: hidden unless asked for, with NULL
+    // treated as not archived. Only applies once the column exists.
+    if schema.hasArchived && !filters.IncludeArchived {
+        clauses = append(clauses, "p.archived IS NOT TRUE")
+    }
+
+    // Search ignores case and diacritics on both sides, so "Medvedev" finds
+    // "Médvedev" and the other way round. %, _ and \ in the term are literal.
     if filters.Search != "" {
-        like := fmt.Sprintf("%%%s%%", strings.ToLower(filters.Search))
-        addClause(fmt.Sprintf("(LOWER(p.tournament) LIKE $%d OR LOWER(p.player1) LIKE $%d OR LOWER(p.player2) LIKE $%d)", len(args)+1, len(args)+1, len(args)+1), like)
+        term := foldExpr(fmt.Sprintf("$%d::text", len(args)+1))
+        like := "'%' || " + term + " || '%'"
+        addClause(fmt.Sprintf("(%s LIKE %s OR %s LIKE %s OR %s LIKE %s)",
+            foldExpr("p.tournament"), like, foldExpr("p.player1"), like, foldExpr("p.player2"), like), likeEscaper.Replace(filters.Search))
     }
 
     if filters.Tournament != "" {
         addClause(fmt.Sprintf("p.tournament = $%d", len(args)+1), filters.Tournament)
     }
 
+    if filters.TournamentLike != "" {
+        addClause(fmt.Sprintf("p.tournament ILIKE '%%' || $%d || '%%'", len(args)+1), likeEscaper.Replace(filters.TournamentLike))
+    }
+
     if filters.Surface != "" {
         addClause(fmt.Sprintf("p.surface = $%d", len(args)+1), filters.Surface)
     }
@@ -490,18 +1569,72 @@ func buildWhereClauses(filters filterSet) ([]string, []any) {
         addClause(fmt.Sprintf("p.learning_phase = $%d", len(args)+1), filters.LearningPhase)
     }
 
-    if filters.RecommendedAction != "" {
-        addClause(fmt.Sprintf("p.recommended_action = $%d", len(args)+1), filters.RecommendedAction)
+    // Unbucketed rows aren't mismatches, as in the bucket audit.
+    if filters.BucketMismatch {
+        clauses = append(clauses, "NULLIF(p.confidence_bucket, '') IS NOT NULL AND LOWER(p.confidence_bucket) <> "+confidenceBucketExpr)
+    }
+
+    if filters.ConfidenceBucket != "" {
+        addClause(fmt.Sprintf("LOWER(p.confidence_bucket) = $%d", len(args)+1), filters.ConfidenceBucket)
+    }
+
+    // placeholderList binds each value and returns "$a, $b, ..." for an IN
+    // list, numbered after everything bound so far.
+    placeholderList := func(values []string) string {
+        placeholders := make([]string, len(values))
+        for i, v := range values {
+            args = append(args, v)
+            placeholders[i] = fmt.Sprintf("$%d", len(args))
+        }
+        return strings.Join(placeholders, ", ")
+    }
+
+    if len(filters.RecommendedActions) > 0 {
+        clauses = append(clauses, fmt.Sprintf("p.recommended_action IN (%s)", placeholderList(filters.RecommendedActions)))
+    }
+
+    // Exclusions keep rows with no tournament/surface, which a bare NOT IN
+    // would drop.
+    if len(filters.ExcludeTournaments) > 0 {
+        clauses = append(clauses, fmt.Sprintf("(p.tournament IS NULL OR p.tournament NOT IN (%s))", placeholderList(filters.ExcludeTournaments)))
+    }
+
+    if len(filters.ExcludeSurfaces) > 0 {
+        clauses = append(clauses, fmt.Sprintf("(p.surface IS NULL OR p.surface NOT IN (%s))", placeholderList(filters.ExcludeSurfaces)))
     }
 
     if filters.PredictionCorrect != nil {
         addClause(fmt.Sprintf("p.prediction_correct = $%d", len(args)+1), *filters.PredictionCorrect)
     }
 
+    if filters.PredictionUnresolved {
+        clauses = append(clauses, "p.prediction_correct IS NULL")
+    }
+
     if filters.ValueBet != nil {
         addClause(fmt.Sprintf("p.value_bet = $%d", len(args)+1), *filters.ValueBet)
     }
 
+    if filters.MarketAgrees != nil {
+        addClause(fmt.Sprintf("%s = $%d", marketAgreesExpr, len(args)+1), *filters.MarketAgrees)
+    }
+
+    if filters.InvalidOdds != nil {
+        if *filters.InvalidOdds {
+            clauses = append(clauses, invalidOddsExpr)
+        } else {
+            clauses = append(clauses, "NOT "+invalidOddsExpr)
+        }
+    }
+
+    if filters.HasReasoning != nil {
+        clauses = append(clauses, presenceClause("p.reasoning", *filters.HasReasoning))
+    }
+
+    if filters.HasRiskAssessment != nil {
+        clauses = append(clauses, presenceClause("p.risk_assessment", *filters.HasRiskAssessment))
+    }
+
     if filters.MinConfidence != nil {
         addClause(fmt.Sprintf("p.confidence_score >= $%d", len(args)+1), *filters.MinConfidence)
     }
@@ -510,26 +1643,127 @@ func buildWhereClauses(filters filterSet) ([]string, []any) {
         addClause(fmt.Sprintf("p.confidence_score <= $%d", len(args)+1), *filters.MaxConfidence)
     }
 
+    if filters.MinConfidenceDistance != nil {
+        addClause(fmt.Sprintf("ABS(p.confidence_score - 50) >= $%d", len(args)+1), *filters.MinConfidenceDistance)
+    }
+
+    // Odds spread separates coin-flip matchups from lopsided ones. Rows with
+    // missing or zero odds would report a meaningless spread, so skip them.
+    if filters.MinOddsSpread != nil || filters.MaxOddsSpread != nil {
+        clauses = append(clauses, "p.odds_player1 > 0 AND p.odds_player2 > 0")
+    }
+
+    if filters.MinOddsSpread != nil {
+        addClause(fmt.Sprintf("ABS(p.odds_player1 - p.odds_player2) >= $%d", len(args)+1), *filters.MinOddsSpread)
+    }
+
+    if filters.MaxOddsSpread != nil {
+        addClause(fmt.Sprintf("ABS(p.odds_player1 - p.odds_player2) <= $%d", len(args)+1), *filters.MaxOddsSpread)
+    }
+
+    // dateFrom/dateTo cover whole days: the upper bound is the start of the
+    // day after dateTo, so rows are included for the full day even if
+    // prediction_day ever carries a time component.
     if filters.DateFrom != nil {
-        addClause(fmt.Sprintf("p.prediction_day >= $%d", len(args)+1), *filters.DateFrom)
+        addClause(fmt.Sprintf("p.prediction_day >= $%d::date", len(args)+1), filters.DateFrom.Format("2006-01-02"))
     }
 
     if filters.DateTo != nil {
-        addClause(fmt.Sprintf("p.prediction_day <= $%d", len(args)+1), *filters.DateTo)
+        addClause(fmt.Sprintf("p.prediction_day < $%d::date + 1", len(args)+1), filters.DateTo.Format("2006-01-02"))
+    }
+
+    // tzParam binds the caller's timezone once, the first time a clause needs
+    // it. dateFrom/dateTo are plain calendar days; only "today" and
+    // timestamp columns need converting.
+    tzParam := ""
+    userTZ := func() string {
+        if tzParam == "" {
+            args = append(args, filters.Timezone)
+            tzParam = fmt.Sprintf("$%d", len(args))
+        }
+        return tzParam
+    }
+
+    // withinDays=N covers today through N days ahead; a negative N covers the
+    // last N days through today, with "today" taken in the caller's timezone.
+    if filters.WithinDays != nil {
+        today := fmt.Sprintf("(NOW() AT TIME ZONE %s)::date", userTZ())
+        n := len(args) + 1
+        addClause(fmt.Sprintf("p.prediction_day BETWEEN LEAST(%s, %s + $%d::int) AND GREATEST(%s, %s + $%d::int)", today, today, n, today, today, n), *filters.WithinDays)
+    }
+
+    // updatedSince returns deltas for polling clients: rows created, or whose
+    // live overlay changed, after the given time.
+    if filters.UpdatedSince != nil {
+        n := len(args) + 1
+        addClause(fmt.Sprintf("(p.created_at > $%d OR l.last_updated > $%d)", n, n), *filters.UpdatedSince)
+    }
+
+    // prediction_day is a DATE with no kick-off time, so the match is taken to
+    // start at local midnight in the caller's timezone; created_at is a
+    // timestamptz and the difference is a real interval.
+    if filters.MinLeadTimeHours != nil {
+        tz := userTZ()
+        addClause(fmt.Sprintf("(p.prediction_day::timestamp AT TIME ZONE %s) - p.created_at >= make_interval(hours => $%d::int)", tz, len(args)+1), *filters.MinLeadTimeHours)
+    }
+
+    if filters.LiveStaleMinutes != nil {
+        addClause(fmt.Sprintf("l.live_status = 'live' AND l.last_updated < NOW() - make_interval(mins => $%d::int)", len(args)+1), *filters.LiveStaleMinutes)
+    }
+
+    // The resolved date is when the live feed last touched a finished match.
+    // Rows without a live result can't have one, so they drop out here.
+    if filters.ResolvedFrom != nil || filters.ResolvedTo != nil {
+        clauses = append(clauses, "l.actual_winner IS NOT NULL AND l.actual_winner != ''")
+    }
+
+    // last_updated is a timestamp, so the bounds are local midnights in the
+    // caller's timezone and the end day is included in full.
+    if filters.ResolvedFrom != nil {
+        tz := userTZ()
+        addClause(fmt.Sprintf("l.last_updated >= ($%d::date::timestamp AT TIME ZONE %s)", len(args)+1, tz), filters.ResolvedFrom.Format("2006-01-02"))
+    }
+
+    if filters.ResolvedTo != nil {
+        tz := userTZ()
+        addClause(fmt.Sprintf("l.last_updated < (($%d::date + 1)::timestamp AT TIME ZONE %s)", len(args)+1, tz), filters.ResolvedTo.Format("2006-01-02"))
+    }
+
+    if filters.LiveAtRisk {
+        clauses = append(clauses, liveAtRiskExpr)
+    }
+
+    if filters.ResolvedOnly {
+        clauses = append(clauses, actualWinnerExpr+" IS NOT NULL")
     }
 
     return clauses, args
 }
 
+// splitCSV splits a comma-separated query value, trimming whitespace and
+// dropping empty entries. It returns nil when nothing is left.
+func splitCSV(raw string) []string {
+    var values []string
+    for _, part := range strings.Split(raw, ",") {
+        if v := strings.TrimSpace(part); v != "" {
+            values = append(values, v)
+        }
+    }
+    return values
+}
+
+// sortableColumns are the sortBy values writeOrderBy accepts.
+var sortableColumns = map[string]struct{}{
+    "prediction_day":                {},
+    "created_at":                    {},
+    "confidence_score":              {},
+    "system_accuracy_at_prediction": {},
+    "predicted_odds":                {},
+    "random":                        {},
+}
+
 func sanitizeSortBy(raw string) string {
-    allowed := map[string]struct{}{
-        "prediction_day": {},
-        "created_at":    {},
-        "confidence_score": {},
-        "system_accuracy_at_prediction": {},
-        "predicted_odds": {},
-    }
-    if _, ok := allowed[raw]; ok {
+    if _, ok := sortableColumns[raw]; ok {
         return raw
     }
     return ""
@@ -543,8 +1777,91 @@ func sanitizeSortDir(raw string) string {
     return ""
 }
 
-func parseIntQuery(r *http.Request, key string, fallback int) int {
-    v := strings.TrimSpace(r.URL.Query().Get(key))
+// maxPageOffset, from MAX_PAGE_OFFSET, is the deepest OFFSET page-number
+// pagination may reach. Postgres reads and discards every skipped row, so
+// a huge page would be an expensive scan; deeper reads should use cursor.
+var maxPageOffset = 50000
+
+// parsePagination reads page and pageSize. It fails when the page would
+// start past maxPageOffset rows. A cursor replaces the offset, so with one
+// present page is read as 1 and the depth limit doesn't apply.
+func parsePagination(r *http.Request) (int, int, error) {
+    page := parseIntQuery(r, paramPage, 1)
+    if page < 1 || strings.TrimSpace(paramCursor.get(r)) != "" {
+        page = 1
+    }
+    // X-Page-Size lets proxies set a default for embeds; the pageSize query
+    // param still wins, and both go through the same clamping.
+    defaultSize := 25
+    if v := strings.TrimSpace(r.Header.Get("X-Page-Size")); v != "" {
+        if n, err := strconv.Atoi(v); err == nil {
+            defaultSize = n
+        }
+    }
+    pageSize := parseIntQuery(r, paramPageSize, defaultSize)
+    if pageSize < 1 {
+        pageSize = 25
+    }
+    if pageSize > 1000 {
+        pageSize = 1000
+    }
+    // Compared by division so a huge page can't overflow the multiplication.
+    if page-1 > maxPageOffset/pageSize {
+        return 0, 0, fmt.Errorf("page %d is too deep: at most %d rows can be skipped", page, maxPageOffset)
+    }
+    return page, pageSize, nil
+}
+
+// envInt reads an integer env var, returning fallback when unset or invalid.
+func envInt(key string, fallback int) int {
+    v := strings.TrimSpace(os.Getenv(key))
+    if v == "" {
+        return fallback
+    }
+    n, err := strconv.Atoi(v)
+    if err != nil {
+        log.Printf("invalid %s=%q, using %d", key, v, fallback)
+        return fallback
+    }
+    return n
+}
+
+// envBool reads a boolean env var, returning fallback when unset or invalid.
+func envBool(key string, fallback bool) bool {
+    v := strings.TrimSpace(os.Getenv(key))
+    if v == "" {
+        return fallback
+    }
+    b, err := strconv.ParseBool(v)
+    if err != nil {
+        log.Printf("invalid %s=%q, using %t", key, v, fallback)
+        return fallback
+    }
+    return b
+}
+
+// parseConfidenceParam reads a confidence bound on the 0-100 scale of
+// confidence_score. Values up to 1 are fractions scaled to the nearest
+// percent, so 1, 1.0 and 100 all mean 100 and 0.6 means 60; larger values
+// are whole percentages.
+func parseConfidenceParam(r *http.Request, key queryParam) (*int, error) {
+    v := strings.TrimSpace(key.get(r))
+    if v == "" {
+        return nil, nil
+    }
+    f, err := strconv.ParseFloat(v, 64)
+    if err != nil || math.IsNaN(f) || f < 0 || f > 100 || f > 1 && f != math.Trunc(f) {
+        return nil, fmt.Errorf("%s must be a fraction 0-1 or a whole percentage up to 100", key)
+    }
+    if f <= 1 {
+        f *= 100
+    }
+    n := int(math.Round(f))
+    return &n, nil
+}
+
+func parseIntQuery(r *http.Request, key queryParam, fallback int) int {
+    v := strings.TrimSpace(key.get(r))
     if v == "" {
         return fallback
     }
@@ -555,16 +1872,39 @@ func parseIntQuery(r *http.Request, key string, fallback int) int {
     return n
 }
 
+// parseIntInRange reads an optional integer param in [lo, hi], returning
+// fallback when it is absent. Anything else is an error naming the range,
+// so a bad value is rejected rather than quietly replaced.
+func parseIntInRange(r *http.Request, key queryParam, fallback, lo, hi int) (int, error) {
+    v := strings.TrimSpace(key.get(r))
+    if v == "" {
+        return fallback, nil
+    }
+    n, err := strconv.Atoi(v)
+    if err != nil || n < lo || n > hi {
+        return 0, fmt.Errorf("%s must be a whole number from %d to %d", key, lo, hi)
+    }
+    return n, nil
+}
+
 func httpError(w http.ResponseWriter, err error, status int) {
-    var pgErr *pgconn.PgError
-    if errors.As(err, &pgErr) {
-        log.Printf("pg error: %v", pgErr)
-    } else {
-        log.Printf("error: %v", err)
+    if !recordFailure(w, err) {
+        httpErrorLog(err)
     }
     respondJSONWithStatus(w, status, map[string]string{"error": "internal server error"})
 }
 
+// httpErrorLog logs an internal error, for paths that can no longer change
+// the response status (e.g. mid-stream).
+func httpErrorLog(err error) {
+    log.Print(describeError(err))
+}
+
+// respondError writes a JSON error body with a message safe to show clients.
+func respondError(w http.ResponseWriter, status int, message string) {
+    respondJSONWithStatus(w, status, map[string]string{"error": message})
+}
+
 func respondJSON(w http.ResponseWriter, payload any) {
     respondJSONWithStatus(w, http.StatusOK, payload)
 }
@@ -577,6 +1917,31 @@ func respondJSONWithStatus(w http.ResponseWriter, status int, payload any) {
     }
 }
 
+// daysUntil is the whole number of days from now's calendar date (server
+// clock) to day, negative for past matches and nil when day is unknown.
+func daysUntil(day *time.Time, now time.Time) *int {
+    if day == nil {
+        return nil
+    }
+    from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+    to := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+    days := int(to.Sub(from).Hours() / 24)
+    return &days
+}
+
+func roundTo(v float64, places int) float64 {
+    scale := math.Pow(10, float64(places))
+    return math.Round(v*scale) / scale
+}
+
+func roundPtr(v *float64, places int) *float64 {
+    if v == nil {
+        return nil
+    }
+    rounded := roundTo(*v, places)
+    return &rounded
+}
+
 func intDivCeil(numerator, denominator int) int {
     if denominator == 0 {
         return 0
@@ -0,0 +1,470 @@
+package main
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+)
+
+// likeMatch evaluates value LIKE pattern the way PostgreSQL does with its
+// default backslash escape, so escaped patterns can be checked without a
+// database.
+func likeMatch(t *testing.T, value, pattern string) bool {
+    t.Helper()
+    var re strings.Builder
+    re.WriteString(`(?s)^`)
+    runes := []rune(pattern)
+    for i := 0; i < len(runes); i++ {
+        switch c := runes[i]; c {
+        case '\\':
+            i++
+            if i == len(runes) {
+                t.Fatalf("pattern %q ends with a bare escape", pattern)
+            }
+            re.WriteString(regexp.QuoteMeta(string(runes[i])))
+        case '%':
+            re.WriteString(`.*`)
+        case '_':
+            re.WriteString(`.`)
+        default:
+            re.WriteString(regexp.QuoteMeta(string(c)))
+        }
+    }
+    re.WriteString(`$`)
+    return regexp.MustCompile(re.String()).MatchString(value)
+}
+
+func TestLikeEscaper(t *testing.T) {
+    tests := []struct {
+        in, want string
+    }{
+        {"Nadal", "Nadal"},
+        {"100%", `100\%`},
+        {"a_b", `a\_b`},
+        {`C:\tmp`, `C:\\tmp`},
+        {`%_\`, `\%\_\\`},
+        {`\%`, `\\\%`},
+    }
+    for _, tt := range tests {
+        if got := likeEscaper.Replace(tt.in); got != tt.want {
+            t.Errorf("likeEscaper.Replace(%q) = %q, want %q", tt.in, got, tt.want)
+        }
+    }
+}
+
+// A search for a metacharacter must match it literally, the way the search
+// clause wraps the escaped term in % wildcards.
+func TestSearchMatchesMetacharactersLiterally(t *testing.T) {
+    tests := []struct {
+        search string
+        value  string
+        want   bool
+    }{
+        {"%", "Davis Cup 100% Finals", true},
+        {"%", "Davis Cup Finals", false},
+        {"100%", "100% Open", true},
+        {"100%", "1000 Masters", false},
+        {"_", "next_gen finals", true},
+        {"_", "Next Gen Finals", false},
+        {"a_b", "a_b", true},
+        {"a_b", "axb", false},
+        {`\`, `back\slash`, true},
+        {`\`, "backslash", false},
+        {"Nadal", "Rafael Nadal", true},
+    }
+    for _, tt := range tests {
+        pattern := "%" + likeEscaper.Replace(tt.search) + "%"
+        if got := likeMatch(t, tt.value, pattern); got != tt.want {
+            t.Errorf("search %q against %q = %v, want %v (pattern %q)", tt.search, tt.value, got, tt.want, pattern)
+        }
+    }
+}
+
+func TestLimitSearch(t *testing.T) {
+    defer func(old int) { searchMaxLength = old }(searchMaxLength)
+    searchMaxLength = 5
+
+    tests := []struct {
+        in, want string
+    }{
+        {"", ""},
+        {"   ", ""},
+        {" Nad ", "Nad"},
+        {"Nadal", "Nadal"},
+        {"Nadalito", "Nadal"},
+        {"%%%%%%%%%%", "%%%%%"},
+        {"Thiem Dominic", "Thiem"},
+        // Cut inside the gap, then trimmed.
+        {"ab   cd", "ab"},
+        // Counted in characters, not bytes.
+        {"Médvédev", "Médvé"},
+    }
+    for _, tt := range tests {
+        if got := limitSearch(tt.in); got != tt.want {
+            t.Errorf("limitSearch(%q) = %q, want %q", tt.in, got, tt.want)
+        }
+    }
+
+    searchMaxLength = 0
+    long := strings.Repeat("%", 500)
+    if got := limitSearch(long); got != long {
+        t.Errorf("limitSearch with no limit cut the term to %d characters", len(got))
+    }
+}
+
+var placeholderRe = regexp.MustCompile(`\$(\d+)`)
+
+// boundValues returns the args each of clause's placeholders refers to, in
+// order, failing if one is out of range.
+func boundValues(t *testing.T, clause string, args []any) []any {
+    t.Helper()
+    var values []any
+    for _, m := range placeholderRe.FindAllStringSubmatch(clause, -1) {
+        n, err := strconv.Atoi(m[1])
+        if err != nil || n < 1 || n > len(args) {
+            t.Fatalf("placeholder $%s in %q has no arg (%d args)", m[1], clause, len(args))
+        }
+        values = append(values, args[n-1])
+    }
+    return values
+}
+
+// findClause returns the one clause starting with prefix.
+func findClause(t *testing.T, clauses []string, prefix string) string {
+    t.Helper()
+    var found []string
+    for _, c := range clauses {
+        if strings.HasPrefix(c, prefix) {
+            found = append(found, c)
+        }
+    }
+    if len(found) != 1 {
+        t.Fatalf("want one clause starting %q, got %q", prefix, found)
+    }
+    return found[0]
+}
+
+// Every placeholder is used exactly once, in order, so no arg is skipped or
+// bound twice.
+func assertPlaceholdersSequential(t *testing.T, clauses []string, args []any) {
+    t.Helper()
+    next := 1
+    for _, m := range placeholderRe.FindAllStringSubmatch(strings.Join(clauses, " AND "), -1) {
+        if m[1] != strconv.Itoa(next) {
+            t.Fatalf("placeholder $%s out of sequence, want $%d in %q", m[1], next, clauses)
+        }
+        next++
+    }
+    if next-1 != len(args) {
+        t.Fatalf("%d placeholders for %d args", next-1, len(args))
+    }
+}
+
+func TestBuildWhereClausesIncludeExclude(t *testing.T) {
+    filters := filterSet{
+        Tournament:         "Wimbledon",
+        Surface:            "Grass",
+        RecommendedActions: []string{"bet", "strong_bet"},
+        ExcludeTournaments: []string{"Challenger Lyon", "ITF M25"},
+        ExcludeSurfaces:    []string{"Clay", "Carpet", "Hard"},
+    }
+    valueBet := true
+    filters.ValueBet = &valueBet
+    clauses, args := buildWhereClauses(filters)
+    assertPlaceholdersSequential(t, clauses, args)
+
+    tests := []struct {
+        prefix string
+        want   []any
+    }{
+        {"p.tournament = ", []any{"Wimbledon"}},
+        {"p.surface = ", []any{"Grass"}},
+        {"p.recommended_action IN ", []any{"bet", "strong_bet"}},
+        {"(p.tournament IS NULL OR p.tournament NOT IN ", []any{"Challenger Lyon", "ITF M25"}},
+        {"(p.surface IS NULL OR p.surface NOT IN ", []any{"Clay", "Carpet", "Hard"}},
+        {"p.value_bet = ", []any{true}},
+    }
+    for _, tt := range tests {
+        clause := findClause(t, clauses, tt.prefix)
+        got := boundValues(t, clause, args)
+        if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+            t.Errorf("%s binds %v, want %v", clause, got, tt.want)
+        }
+    }
+}
+
+func TestBuildWhereClausesExcludeOnly(t *testing.T) {
+    clauses, args := buildWhereClauses(filterSet{
+        ExcludeTournaments: []string{"Davis Cup"},
+        ExcludeSurfaces:    []string{"Carpet"},
+    })
+    assertPlaceholdersSequential(t, clauses, args)
+    want := []string{
+        "(p.tournament IS NULL OR p.tournament NOT IN ($1))",
+        "(p.surface IS NULL OR p.surface NOT IN ($2))",
+    }
+    if strings.Join(clauses, "\n") != strings.Join(want, "\n") {
+        t.Errorf("clauses = %q, want %q", clauses, want)
+    }
+}
+
+// dateTo covers its whole day: the bound is the start of the next day, so a
+// prediction_day timestamped late on dateTo is still in range.
+func TestBuildWhereClausesDateToCoversWholeDay(t *testing.T) {
+    dateTo := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+    dateFrom := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+    clauses, args := buildWhereClauses(filterSet{DateFrom: &dateFrom, DateTo: &dateTo})
+    assertPlaceholdersSequential(t, clauses, args)
+
+    upper := findClause(t, clauses, "p.prediction_day < ")
+    if upper != "p.prediction_day < $2::date + 1" {
+        t.Fatalf("dateTo clause = %q, want an exclusive bound on the next day", upper)
+    }
+    lower := findClause(t, clauses, "p.prediction_day >= ")
+    if lower != "p.prediction_day >= $1::date" {
+        t.Fatalf("dateFrom clause = %q", lower)
+    }
+    if got := boundValues(t, upper, args); fmt.Sprint(got) != "[2024-06-01]" {
+        t.Fatalf("dateTo binds %v, want the bare date", got)
+    }
+
+    // Apply the bounds as PostgreSQL would: date + 1 is midnight after dateTo.
+    from, _ := time.Parse("2006-01-02", boundValues(t, lower, args)[0].(string))
+    to, _ := time.Parse("2006-01-02", boundValues(t, upper, args)[0].(string))
+    to = to.AddDate(0, 0, 1)
+    tests := []struct {
+        day  time.Time
+        want bool
+    }{
+        {time.Date(2024, 5, 30, 23, 59, 59, 0, time.UTC), false},
+        {time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), true},
+        {time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), true},
+        {time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC), true},
+        {time.Date(2024, 6, 1, 23, 59, 59, 999999000, time.UTC), true},
+        {time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC), false},
+    }
+    for _, tt := range tests {
+        in := !tt.day.Before(from) && tt.day.Before(to)
+        if in != tt.want {
+            t.Errorf("prediction_day %s in range = %v, want %v", tt.day.Format(time.RFC3339Nano), in, tt.want)
+        }
+    }
+}
+
+func TestCollectFiltersDateTo(t *testing.T) {
+    r := httptest.NewRequest(http.MethodGet, "/api/predictions?dateTo=2024-06-01", nil)
+    filters, err := collectFilters(r)
+    if err != nil {
+        t.Fatal(err)
+    }
+    clauses, args := buildWhereClauses(filters)
+    upper := findClause(t, clauses, "p.prediction_day < ")
+    if got := boundValues(t, upper, args); fmt.Sprint(got) != "[2024-06-01]" {
+        t.Errorf("dateTo=2024-06-01 binds %v", got)
+    }
+}
+
+// predictionColumnTypes reads the predictions table's column types from
+// database/schema.sql.
+func predictionColumnTypes(t *testing.T) map[string]string {
+    t.Helper()
+    schemaSQL, err := os.ReadFile("../../database/schema.sql")
+    if err != nil {
+        t.Skipf("schema not available: %v", err)
+    }
+    src := string(schemaSQL)
+    start := strings.Index(src, "CREATE TABLE predictions (")
+    if start < 0 {
+        t.Fatal("predictions table not found in schema.sql")
+    }
+    end := strings.Index(src[start:], ");")
+    types := map[string]string{}
+    for _, line := range strings.Split(src[start:start+end], "\n")[1:] {
+        fields := strings.Fields(strings.TrimSpace(line))
+        if len(fields) >= 2 {
+            types[fields[0]] = strings.ToUpper(strings.TrimSuffix(fields[1], ","))
+        }
+    }
+    return types
+}
+
+// Every sort must order numerically or chronologically, never as text: a
+// computed sort is cast to numeric and a plain column must have a numeric
+// or date/time type in the schema.
+func TestWriteOrderByNumericSorts(t *testing.T) {
+    types := predictionColumnTypes(t)
+    orderedTypes := []string{"INTEGER", "SERIAL", "NUMERIC", "DATE", "TIMESTAMP"}
+
+    for sortBy := range sortableColumns {
+        if sortBy == "random" {
+            continue
+        }
+        t.Run(sortBy, func(t *testing.T) {
+            var base strings.Builder
+            writeOrderBy(&base, filterSet{SortBy: sortBy, SortDir: "ASC"}, nil)
+            orderBy := strings.TrimPrefix(base.String(), " ORDER BY ")
+            key, _, _ := strings.Cut(orderBy, " ASC")
+
+            if column, ok := strings.CutPrefix(key, "p."); ok {
+                colType := types[column]
+                ordered := false
+                for _, prefix := range orderedTypes {
+                    ordered = ordered || strings.HasPrefix(colType, prefix)
+                }
+                if !ordered {
+                    t.Errorf("sort on %s has schema type %q, which would sort lexically; cast it", column, colType)
+                }
+                return
+            }
+            if !strings.HasSuffix(key, ")::numeric") {
+                t.Errorf("computed sort %q is not cast to numeric", key)
+            }
+        })
+    }
+}
+
+func TestWriteOrderByPredictedOdds(t *testing.T) {
+    var base strings.Builder
+    writeOrderBy(&base, filterSet{SortBy: "predicted_odds", SortDir: "DESC"}, nil)
+    want := " ORDER BY (" + predictedOddsExpr + ")::numeric DESC, p.prediction_id DESC"
+    if base.String() != want {
+        t.Errorf("ORDER BY = %q, want %q", base.String(), want)
+    }
+}
+
+// liveAtRisk must filter in SQL, so the page, the count and the cursor all
+// see the same rows.
+func TestLiveAtRiskFiltersInSQL(t *testing.T) {
+    filters := filterSet{LiveAtRisk: true}
+    page, args := buildPredictionQuery(filters, 1, 20)
+    count, countArgs := buildPredictionCountQuery(filters)
+    for name, query := range map[string]string{"page": page, "count": count} {
+        _, where, ok := strings.Cut(query, " WHERE ")
+        if !ok || !strings.Contains(where, liveAtRiskExpr) {
+            t.Errorf("%s query does not apply liveAtRiskExpr in WHERE: %s", name, query)
+        }
+    }
+    if strings.Index(page, liveAtRiskExpr) > strings.Index(page, " LIMIT ") {
+        t.Errorf("liveAtRiskExpr comes after LIMIT: %s", page)
+    }
+    if len(args) != len(countArgs)+2 {
+        t.Errorf("page has %d args, count %d; want only LIMIT/OFFSET extra", len(args), len(countArgs))
+    }
+}
+
+func TestPageLastModified(t *testing.T) {
+    at := func(hour int) *time.Time {
+        t := time.Date(2024, 6, 1, hour, 0, 0, 0, time.UTC)
+        return &t
+    }
+    tests := []struct {
+        name string
+        rows []prediction
+        want *time.Time
+    }{
+        {"no rows", nil, nil},
+        {"no timestamps", []prediction{{PredictionID: 1}}, nil},
+        {"created only", []prediction{{CreatedAt: at(3)}, {CreatedAt: at(5)}, {CreatedAt: at(4)}}, at(5)},
+        {"live update is newer", []prediction{{CreatedAt: at(3), LastUpdated: at(9)}, {CreatedAt: at(5)}}, at(9)},
+        {"live update on another row", []prediction{{CreatedAt: at(8)}, {LastUpdated: at(6)}}, at(8)},
+    }
+    for _, tt := range tests {
+        got := pageLastModified(tt.rows)
+        if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) {
+            t.Errorf("%s: pageLastModified = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestRequireLiveData(t *testing.T) {
+    defer func(old bool) { liveDataEnabled = old }(liveDataEnabled)
+    next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusNoContent)
+    })
+    for _, enabled := range []bool{true, false} {
+        liveDataEnabled = enabled
+        rec := httptest.NewRecorder()
+        requireLiveData(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/predictions/conflicts", nil))
+        want := http.StatusNoContent
+        if !enabled {
+            want = http.StatusServiceUnavailable
+        }
+        if rec.Code != want {
+            t.Errorf("liveDataEnabled=%v: status = %d, want %d", enabled, rec.Code, want)
+        }
+    }
+}
+
+func TestParsePaginationDepth(t *testing.T) {
+    deep := fmt.Sprintf("/api/predictions?pageSize=100&page=%d", maxPageOffset/100+2)
+    if _, _, err := parsePagination(httptest.NewRequest(http.MethodGet, deep, nil)); err == nil {
+        t.Errorf("%s: want a too-deep error", deep)
+    }
+    // A cursor resumes from its own position, so the page is ignored.
+    page, pageSize, err := parsePagination(httptest.NewRequest(http.MethodGet, deep+"&cursor=abc", nil))
+    if err != nil || page != 1 || pageSize != 100 {
+        t.Errorf("with cursor: page %d, pageSize %d, err %v; want 1, 100, nil", page, pageSize, err)
+    }
+}
+
+func TestParseIntInRange(t *testing.T) {
+    tests := []struct {
+        query string
+        want  int
+        ok    bool
+    }{
+        {"", 50, true},
+        {"window=1", 1, true},
+        {"window=1000", 1000, true},
+        {"window=0", 0, false},
+        {"window=1001", 0, false},
+        {"window=5000", 0, false},
+        {"window=abc", 0, false},
+    }
+    for _, tt := range tests {
+        r := httptest.NewRequest(http.MethodGet, "/api/stats/rolling?"+tt.query, nil)
+        got, err := parseIntInRange(r, paramWindow, 50, 1, 1000)
+        if (err == nil) != tt.ok || got != tt.want {
+            t.Errorf("%q: got %d, %v; want %d, ok %v", tt.query, got, err, tt.want, tt.ok)
+        }
+        if err != nil && !strings.Contains(err.Error(), "from 1 to 1000") {
+            t.Errorf("%q: error %q does not name the range", tt.query, err)
+        }
+    }
+}
+
+func TestParseConfidenceParam(t *testing.T) {
+    tests := []struct {
+        value string
+        want  int
+        ok    bool
+    }{
+        {"1", 100, true},
+        {"1.0", 100, true},
+        {"0.6", 60, true},
+        {"0", 0, true},
+        {"60", 60, true},
+        {"100", 100, true},
+        {"60.5", 0, false},
+        {"101", 0, false},
+        {"-0.1", 0, false},
+        {"abc", 0, false},
+    }
+    for _, tt := range tests {
+        r := httptest.NewRequest(http.MethodGet, "/api/predictions?minConfidence="+tt.value, nil)
+        got, err := parseConfidenceParam(r, paramMinConfidence)
+        if (err == nil) != tt.ok {
+            t.Errorf("%q: err = %v, want ok %v", tt.value, err, tt.ok)
+            continue
+        }
+        if tt.ok && *got != tt.want {
+            t.Errorf("%q = %d, want %d", tt.value, *got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/go-chi/chi/v5"
+    "github.com/jackc/pgx/v5"
+)
+
+type neighborsResponse struct {
+    PredictionID int  `json:"prediction_id"`
+    PreviousID   *int `json:"previous_id"`
+    NextID       *int `json:"next_id"`
+}
+
+// handlePredictionNeighbors returns the ids just before and after a
+// prediction in the list as the same filters and sort would page it, for
+// prev/next navigation. Either side is null at the ends of the list, and
+// the prediction must itself match the filters.
+func (s *server) handlePredictionNeighbors(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "invalid prediction id")
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    if filters.SortBy == "random" && filters.Seed == "" {
+        respondError(w, http.StatusBadRequest, "sortBy=random needs a seed to have stable neighbors")
+        return
+    }
+
+    clauses, args := buildWhereClauses(filters)
+    order := strings.Builder{}
+    args = writeOrderBy(&order, filters, args)
+    window := fmt.Sprintf("OVER (%s)", strings.TrimSpace(order.String()))
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT previous_id, next_id FROM (SELECT
+        p.prediction_id,
+        LAG(p.prediction_id) ` + window + ` AS previous_id,
+        LEAD(p.prediction_id) ` + window + ` AS next_id
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    args = append(args, id)
+    base.WriteString(fmt.Sprintf(") ordered WHERE prediction_id = $%d", len(args)))
+
+    resp := neighborsResponse{PredictionID: id}
+    err = s.db.QueryRow(r.Context(), base.String(), args...).Scan(&resp.PreviousID, &resp.NextID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        respondError(w, http.StatusNotFound, "prediction not found in the filtered set")
+        return
+    }
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, resp)
+}
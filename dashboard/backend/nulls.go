@@ -0,0 +1,90 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "reflect"
+    "strings"
+)
+
+// wantExplicitNulls reports whether the client asked for every optional
+// field to be present, as null when unset, instead of omitted. It is
+// requested with ?nulls=explicit or the X-Nulls: explicit header.
+func wantExplicitNulls(r *http.Request) bool {
+    v := paramNulls.get(r)
+    if v == "" {
+        v = r.Header.Get("X-Nulls")
+    }
+    return strings.EqualFold(strings.TrimSpace(v), "explicit")
+}
+
+// predictionRows is a list of predictions that can encode either compactly
+// (the struct tags' omitempty) or with explicit nulls, so one struct
+// definition serves both policies.
+type predictionRows struct {
+    rows     []prediction
+    explicit bool
+}
+
+func newPredictionRows(r *http.Request, rows []prediction) predictionRows {
+    return predictionRows{rows: rows, explicit: wantExplicitNulls(r)}
+}
+
+// MarshalJSON always encodes a list; a nil slice becomes [] rather than null.
+func (p predictionRows) MarshalJSON() ([]byte, error) {
+    if p.rows == nil {
+        return []byte("[]"), nil
+    }
+    if !p.explicit {
+        return json.Marshal(p.rows)
+    }
+    var buf bytes.Buffer
+    buf.WriteByte('[')
+    for i := range p.rows {
+        if i > 0 {
+            buf.WriteByte(',')
+        }
+        if err := writeExplicitFields(&buf, reflect.ValueOf(p.rows[i])); err != nil {
+            return nil, err
+        }
+    }
+    buf.WriteByte(']')
+    return buf.Bytes(), nil
+}
+
+// writeExplicitFields encodes a struct's exported fields in declaration
+// order using their json names, ignoring omitempty so nil pointers are
+// written as null.
+func writeExplicitFields(buf *bytes.Buffer, v reflect.Value) error {
+    t := v.Type()
+    buf.WriteByte('{')
+    first := true
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        if !field.IsExported() {
+            continue
+        }
+        name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+        if name == "-" {
+            continue
+        }
+        if name == "" {
+            name = field.Name
+        }
+        value, err := json.Marshal(v.Field(i).Interface())
+        if err != nil {
+            return err
+        }
+        if !first {
+            buf.WriteByte(',')
+        }
+        first = false
+        key, _ := json.Marshal(name)
+        buf.Write(key)
+        buf.WriteByte(':')
+        buf.Write(value)
+    }
+    buf.WriteByte('}')
+    return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+// An empty page must serialize data as [] under both null policies, with
+// or without meta, whether the slice is nil or empty.
+func TestEmptyPredictionPageData(t *testing.T) {
+    requests := map[string]*http.Request{
+        "default":         httptest.NewRequest(http.MethodGet, "/api/predictions", nil),
+        "explicit param":  httptest.NewRequest(http.MethodGet, "/api/predictions?nulls=explicit", nil),
+        "explicit header": httptest.NewRequest(http.MethodGet, "/api/predictions", nil),
+    }
+    requests["explicit header"].Header.Set("X-Nulls", "explicit")
+
+    for name, r := range requests {
+        for _, rows := range [][]prediction{nil, {}} {
+            meta := pageMeta(0, 1, 20)
+            responses := map[string]func(w http.ResponseWriter){
+                "no meta": func(w http.ResponseWriter) {
+                    respondJSON(w, predictionsResponse{Data: newPredictionRows(r, rows)})
+                },
+                "meta": func(w http.ResponseWriter) {
+                    respondPage(w, meta, predictionsResponse{Data: newPredictionRows(r, rows), Meta: &meta})
+                },
+            }
+            for shape, respond := range responses {
+                rec := httptest.NewRecorder()
+                respond(rec)
+                var body map[string]json.RawMessage
+                if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+                    t.Fatalf("%s/%s: decoding %q: %v", name, shape, rec.Body.String(), err)
+                }
+                if got := string(body["data"]); got != "[]" {
+                    t.Errorf("%s/%s (nil rows %v): data = %s, want []", name, shape, rows == nil, got)
+                }
+            }
+        }
+    }
+}
+
+func TestPredictionRowsExplicitNulls(t *testing.T) {
+    r := httptest.NewRequest(http.MethodGet, "/api/predictions?nulls=explicit", nil)
+    data, err := json.Marshal(newPredictionRows(r, []prediction{{PredictionID: 1}}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var rows []map[string]json.RawMessage
+    if err := json.Unmarshal(data, &rows); err != nil {
+        t.Fatalf("decoding %s: %v", data, err)
+    }
+    if len(rows) != 1 {
+        t.Fatalf("got %d rows, want 1", len(rows))
+    }
+    if got, ok := rows[0]["actual_winner"]; !ok || string(got) != "null" {
+        t.Errorf("actual_winner = %s (present %v), want null", got, ok)
+    }
+
+    compact, err := json.Marshal(newPredictionRows(httptest.NewRequest(http.MethodGet, "/", nil), []prediction{{PredictionID: 1}}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    rows = nil
+    if err := json.Unmarshal(compact, &rows); err != nil {
+        t.Fatalf("decoding %s: %v", compact, err)
+    }
+    if _, ok := rows[0]["actual_winner"]; ok {
+        t.Errorf("actual_winner present in compact encoding: %s", compact)
+    }
+}
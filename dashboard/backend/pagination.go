@@ -0,0 +1,66 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// paginationMeta, from PAGINATION_META, turns meta off server-wide on the
+// prediction lists when false.
+var paginationMeta = true
+
+// wantPageMeta reports whether a prediction list should include meta. It
+// can be dropped server-wide or per request with meta=false, which skips
+// the count query. page and pageSize still select the rows, but there is
+// no total, total_pages, server_time or X-Total-Count; a page shorter than
+// pageSize is the last one. next_cursor moves to the top level, so cursor
+// paging works without the count.
+func wantPageMeta(r *http.Request) bool {
+    if !paginationMeta {
+        return false
+    }
+    v := strings.TrimSpace(paramMeta.get(r))
+    if v == "" {
+        return true
+    }
+    b, err := strconv.ParseBool(v)
+    return err != nil || b
+}
+
+// pageMeta is the standard meta for one page of a list of total items.
+func pageMeta(total, page, pageSize int) responseMeta {
+    return responseMeta{
+        Total:      total,
+        Page:       page,
+        PageSize:   pageSize,
+        TotalPages: intDivCeil(total, pageSize),
+    }
+}
+
+// queryPage runs query, which must have a complete ORDER BY, for one page
+// of results, counting the full result separately for the meta. The caller
+// closes rows.
+func (s *server) queryPage(ctx context.Context, query string, args []any, page, pageSize int) (pgx.Rows, responseMeta, error) {
+    total, err := s.fetchTotal(ctx, "SELECT COUNT(*) FROM ("+query+") counted", args)
+    if err != nil {
+        return nil, responseMeta{}, err
+    }
+    paged := fmt.Sprintf("%s LIMIT $%d OFFSET $%d", query, len(args)+1, len(args)+2)
+    rows, err := s.db.Query(ctx, paged, append(args, pageSize, (page-1)*pageSize)...)
+    if err != nil {
+        return nil, responseMeta{}, err
+    }
+    return rows, pageMeta(total, page, pageSize), nil
+}
+
+// respondPage writes a list-style response, mirroring meta.total in
+// X-Total-Count as every paged endpoint does.
+func respondPage(w http.ResponseWriter, meta responseMeta, payload any) {
+    w.Header().Set("X-Total-Count", strconv.Itoa(meta.Total))
+    respondJSON(w, payload)
+}
@@ -0,0 +1,92 @@
+package main
+
+import "net/http"
+
+// queryParam is the name of a query param some endpoint reads. Params are
+// declared below with newQueryParam and read with get, never by a bare
+// string, so the declarations are the one list of known params: strict=true
+// accepts exactly these, and a new param is known as soon as it exists.
+type queryParam string
+
+// knownQueryParams is every declared query param, filled by newQueryParam.
+var knownQueryParams = map[string]struct{}{}
+
+func newQueryParam(name string) queryParam {
+    knownQueryParams[name] = struct{}{}
+    return queryParam(name)
+}
+
+// get returns the param's first value on r, or "" when it is absent.
+func (p queryParam) get(r *http.Request) string {
+    return r.URL.Query().Get(string(p))
+}
+
+// Filters (collectFilters).
+var (
+    paramSearch                = newQueryParam("search")
+    paramTournament            = newQueryParam("tournament")
+    paramTournamentLike        = newQueryParam("tournamentLike")
+    paramExcludeTournament     = newQueryParam("excludeTournament")
+    paramSurface               = newQueryParam("surface")
+    paramExcludeSurface        = newQueryParam("excludeSurface")
+    paramLearningPhase         = newQueryParam("learningPhase")
+    paramRecommendedAction     = newQueryParam("recommendedAction")
+    paramPredictionCorrect     = newQueryParam("predictionCorrect")
+    paramValueBet              = newQueryParam("valueBet")
+    paramMarketAgrees          = newQueryParam("marketAgrees")
+    paramInvalidOdds           = newQueryParam("invalidOdds")
+    paramHasReasoning          = newQueryParam("hasReasoning")
+    paramHasRiskAssessment     = newQueryParam("hasRiskAssessment")
+    paramMinConfidence         = newQueryParam("minConfidence")
+    paramMaxConfidence         = newQueryParam("maxConfidence")
+    paramMinConfidenceDistance = newQueryParam("minConfidenceDistance")
+    paramMinOddsSpread         = newQueryParam("minOddsSpread")
+    paramMaxOddsSpread         = newQueryParam("maxOddsSpread")
+    paramDateFrom              = newQueryParam("dateFrom")
+    paramDateTo                = newQueryParam("dateTo")
+    paramWithinDays            = newQueryParam("withinDays")
+    paramUpdatedSince          = newQueryParam("updatedSince")
+    paramMinLeadTimeHours      = newQueryParam("minLeadTimeHours")
+    paramLiveStaleMinutes      = newQueryParam("liveStaleMinutes")
+    paramResolvedFrom          = newQueryParam("resolvedFrom")
+    paramResolvedTo            = newQueryParam("resolvedTo")
+    paramSortBy                = newQueryParam("sortBy")
+    paramSortDir               = newQueryParam("sortDir")
+    paramSeed                  = newQueryParam("seed")
+    paramTz                    = newQueryParam("tz")
+    paramIncludeOddsHistory    = newQueryParam("includeOddsHistory")
+    paramLiveAtRisk            = newQueryParam("liveAtRisk")
+    paramIncludeArchived       = newQueryParam("includeArchived")
+    paramBucketMismatch        = newQueryParam("bucketMismatch")
+    paramCursor                = newQueryParam("cursor")
+    paramStrict                = newQueryParam("strict")
+)
+
+// Paging, output and endpoint options.
+var (
+    paramPage          = newQueryParam("page")
+    paramPageSize      = newQueryParam("pageSize")
+    paramMeta          = newQueryParam("meta")
+    paramNulls         = newQueryParam("nulls")
+    paramFormat        = newQueryParam("format")
+    paramLocale        = newQueryParam("locale")
+    paramPreset        = newQueryParam("preset")
+    paramDays          = newQueryParam("days")
+    paramLimit         = newQueryParam("limit")
+    paramOffset        = newQueryParam("offset")
+    paramQ             = newQueryParam("q")
+    paramNames         = newQueryParam("names")
+    paramWindow        = newQueryParam("window")
+    paramYear          = newQueryParam("year")
+    paramMa            = newQueryParam("ma")
+    paramGranularity   = newQueryParam("granularity")
+    paramInitial       = newQueryParam("initial")
+    paramStrategy      = newQueryParam("strategy")
+    paramStake         = newQueryParam("stake")
+    paramKellyFraction = newQueryParam("kellyFraction")
+    paramBankroll      = newQueryParam("bankroll")
+    paramAFrom         = newQueryParam("aFrom")
+    paramATo           = newQueryParam("aTo")
+    paramBFrom         = newQueryParam("bFrom")
+    paramBTo           = newQueryParam("bTo")
+)
@@ -0,0 +1,108 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/url"
+    "os"
+    "sort"
+    "strconv"
+)
+
+// filterPreset is a named set of list query params, e.g. "clay-value-bets"
+// for surface=Clay&valueBet=true&minConfidence=60.
+type filterPreset struct {
+    Name    string            `json:"name"`
+    Filters map[string]string `json:"filters"`
+}
+
+type presetsResponse struct {
+    Data []filterPreset `json:"data"`
+}
+
+// loadPresets reads presets from FILTER_PRESETS_FILE, or inline JSON in
+// FILTER_PRESETS, shaped as {"name": {"param": value, ...}, ...}. Values may
+// be JSON strings, numbers or booleans and are used as query param values;
+// numbers keep their literal form. null, arrays and objects fail the load.
+func loadPresets() (map[string]filterPreset, error) {
+    raw := []byte(os.Getenv("FILTER_PRESETS"))
+    if path := os.Getenv("FILTER_PRESETS_FILE"); path != "" {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return nil, err
+        }
+        raw = data
+    }
+    if len(raw) == 0 {
+        return map[string]filterPreset{}, nil
+    }
+
+    // UseNumber keeps 1000000 as written rather than going through float64,
+    // which would print it as 1e+06.
+    dec := json.NewDecoder(bytes.NewReader(raw))
+    dec.UseNumber()
+    var decoded map[string]map[string]any
+    if err := dec.Decode(&decoded); err != nil {
+        return nil, fmt.Errorf("parse filter presets: %w", err)
+    }
+    presets := make(map[string]filterPreset, len(decoded))
+    for name, params := range decoded {
+        filters := make(map[string]string, len(params))
+        for key, value := range params {
+            switch v := value.(type) {
+            case string:
+                filters[key] = v
+            case json.Number:
+                filters[key] = v.String()
+            case bool:
+                filters[key] = strconv.FormatBool(v)
+            default:
+                return nil, fmt.Errorf("filter preset %q: %s must be a string, number or boolean", name, key)
+            }
+        }
+        presets[name] = filterPreset{Name: name, Filters: filters}
+    }
+    return presets, nil
+}
+
+func (s *server) handleListPresets(w http.ResponseWriter, r *http.Request) {
+    presets := make([]filterPreset, 0, len(s.presets))
+    for _, preset := range s.presets {
+        presets = append(presets, preset)
+    }
+    sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+    respondJSON(w, presetsResponse{Data: presets})
+}
+
+// expandPresets rewrites ?preset=name into the preset's params before any
+// handler parses the query. Params given explicitly on the request win over
+// the preset's values.
+func (s *server) expandPresets(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        query := r.URL.Query()
+        name := paramPreset.get(r)
+        if name == "" {
+            next.ServeHTTP(w, r)
+            return
+        }
+        preset, ok := s.presets[name]
+        if !ok {
+            respondError(w, http.StatusBadRequest, fmt.Sprintf("unknown preset %q", name))
+            return
+        }
+
+        expanded := url.Values{}
+        for key, value := range preset.Filters {
+            expanded.Set(key, value)
+        }
+        for key, values := range query {
+            if key != string(paramPreset) {
+                expanded[key] = values
+            }
+        }
+        r.URL.RawQuery = expanded.Encode()
+        next.ServeHTTP(w, r)
+    })
+}
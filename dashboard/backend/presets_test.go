@@ -0,0 +1,33 @@
+package main
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestLoadPresetsValues(t *testing.T) {
+    t.Setenv("FILTER_PRESETS_FILE", "")
+    t.Setenv("FILTER_PRESETS", `{"big": {"minConfidence": 60, "bankroll": 1000000, "odds": 1.75, "valueBet": true, "surface": "Clay"}}`)
+    presets, err := loadPresets()
+    if err != nil {
+        t.Fatalf("loadPresets: %v", err)
+    }
+    want := map[string]string{"minConfidence": "60", "bankroll": "1000000", "odds": "1.75", "valueBet": "true", "surface": "Clay"}
+    got := presets["big"].Filters
+    for key, value := range want {
+        if got[key] != value {
+            t.Errorf("%s = %q, want %q", key, got[key], value)
+        }
+    }
+}
+
+func TestLoadPresetsRejectsNonScalars(t *testing.T) {
+    t.Setenv("FILTER_PRESETS_FILE", "")
+    for _, value := range []string{`null`, `["Clay"]`, `{"a": 1}`} {
+        t.Setenv("FILTER_PRESETS", `{"bad": {"surface": `+value+`}}`)
+        _, err := loadPresets()
+        if err == nil || !strings.Contains(err.Error(), "surface") {
+            t.Errorf("%s: err = %v, want a load error naming the param", value, err)
+        }
+    }
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+    "net/http"
+    "time"
+)
+
+type duplicateEntry struct {
+    PredictionID int        `json:"prediction_id"`
+    CreatedAt    *time.Time `json:"created_at"`
+}
+
+type duplicateSet struct {
+    MatchID     string           `json:"match_id"`
+    Count       int              `json:"count"`
+    Predictions []duplicateEntry `json:"predictions"`
+}
+
+type duplicatesResponse struct {
+    Data []duplicateSet `json:"data"`
+    Meta responseMeta   `json:"meta"`
+}
+
+// handleListDuplicates lists match_ids that have more than one prediction
+// row, most duplicated first, as a cleanup aid. match_id is unique in the
+// current schema, so this is always empty there and only finds anything on
+// databases that predate that constraint.
+func (s *server) handleListDuplicates(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+    page, pageSize, err := parsePagination(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    rows, meta, err := s.queryPage(ctx, `SELECT
+        match_id,
+        COUNT(*),
+        array_agg(prediction_id ORDER BY created_at, prediction_id),
+        array_agg(created_at ORDER BY created_at, prediction_id)
+        FROM predictions
+        GROUP BY match_id
+        HAVING COUNT(*) > 1
+        ORDER BY COUNT(*) DESC, match_id`, nil, page, pageSize)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    sets := []duplicateSet{}
+    for rows.Next() {
+        var set duplicateSet
+        var ids []int
+        var createdAt []*time.Time
+        if err := rows.Scan(&set.MatchID, &set.Count, &ids, &createdAt); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        set.Predictions = make([]duplicateEntry, len(ids))
+        for i, id := range ids {
+            set.Predictions[i] = duplicateEntry{PredictionID: id, CreatedAt: createdAt[i]}
+        }
+        sets = append(sets, set)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondPage(w, meta, duplicatesResponse{Data: sets, Meta: meta})
+}
+
+type winnerConflict struct {
+    PredictionID     int        `json:"prediction_id"`
+    MatchID          string     `json:"match_id"`
+    PredictionDay    *time.Time `json:"prediction_day"`
+    Player1          string     `json:"player1"`
+    Player2          string     `json:"player2"`
+    PredictionWinner string     `json:"prediction_actual_winner"`
+    LiveWinner       string     `json:"live_actual_winner"`
+    LiveLastUpdated  *time.Time `json:"live_last_updated"`
+}
+
+type winnerConflictsResponse struct {
+    Data []winnerConflict `json:"data"`
+    Meta responseMeta     `json:"meta"`
+}
+
+// handleListConflicts lists predictions whose recorded winner disagrees with
+// the live feed's, a sign the two tables fell out of sync. Both sides are
+// read directly rather than through actualWinnerExpr, which would hide the
+// disagreement. With live data disabled there is nothing to compare, so the
+// route answers 503 instead of an empty list.
+func (s *server) handleListConflicts(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+    page, pageSize, err := parsePagination(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    rows, meta, err := s.queryPage(ctx, `SELECT
+        p.prediction_id,
+        p.match_id,
+        p.prediction_day,
+        p.player1,
+        p.player2,
+        p.actual_winner,
+        l.actual_winner,
+        l.last_updated
+        `+predictionsFrom+`
+        WHERE NULLIF(p.actual_winner, '') IS NOT NULL
+          AND NULLIF(l.actual_winner, '') IS NOT NULL
+          AND p.actual_winner <> l.actual_winner
+        ORDER BY p.prediction_day DESC NULLS LAST, p.prediction_id`, nil, page, pageSize)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    conflicts := []winnerConflict{}
+    for rows.Next() {
+        var c winnerConflict
+        if err := rows.Scan(&c.PredictionID, &c.MatchID, &c.PredictionDay, &c.Player1, &c.Player2,
+            &c.PredictionWinner, &c.LiveWinner, &c.LiveLastUpdated); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        conflicts = append(conflicts, c)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondPage(w, meta, winnerConflictsResponse{Data: conflicts, Meta: meta})
+}
+
+type pickEntry struct {
+    PredictionID    int        `json:"prediction_id"`
+    PredictedWinner string     `json:"predicted_winner"`
+    ConfidenceScore int        `json:"confidence_score"`
+    CreatedAt       *time.Time `json:"created_at"`
+}
+
+type pickFlip struct {
+    MatchID string      `json:"match_id"`
+    Flips   int         `json:"flips"`
+    Picks   []pickEntry `json:"picks"`
+}
+
+type pickFlipsResponse struct {
+    Data []pickFlip   `json:"data"`
+    Meta responseMeta `json:"meta"`
+}
+
+// handleListFlips lists matches whose repeated predictions changed pick,
+// with every pick in created_at order. flips counts how many times
+// consecutive picks differ. match_id is unique in the current schema, so
+// this only finds anything on databases that predate that constraint, and
+// the route is only mounted with DEBUG_ENDPOINTS.
+func (s *server) handleListFlips(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+    page, pageSize, err := parsePagination(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    rows, meta, err := s.queryPage(ctx, `SELECT
+        match_id,
+        COUNT(*) FILTER (WHERE previous_pick IS NOT NULL AND previous_pick <> predicted_winner),
+        array_agg(prediction_id ORDER BY created_at, prediction_id),
+        array_agg(predicted_winner ORDER BY created_at, prediction_id),
+        array_agg(confidence_score ORDER BY created_at, prediction_id),
+        array_agg(created_at ORDER BY created_at, prediction_id)
+        FROM (
+            SELECT match_id, prediction_id, predicted_winner, confidence_score, created_at,
+                LAG(predicted_winner) OVER (PARTITION BY match_id ORDER BY created_at, prediction_id) AS previous_pick
+            FROM predictions
+        ) picks
+        GROUP BY match_id
+        HAVING COUNT(DISTINCT predicted_winner) > 1
+        ORDER BY 2 DESC, match_id`, nil, page, pageSize)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    flips := []pickFlip{}
+    for rows.Next() {
+        var f pickFlip
+        var ids, confidence []int
+        var winners []string
+        var createdAt []*time.Time
+        if err := rows.Scan(&f.MatchID, &f.Flips, &ids, &winners, &confidence, &createdAt); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        f.Picks = make([]pickEntry, len(ids))
+        for i, id := range ids {
+            f.Picks[i] = pickEntry{PredictionID: id, PredictedWinner: winners[i], ConfidenceScore: confidence[i], CreatedAt: createdAt[i]}
+        }
+        flips = append(flips, f)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondPage(w, meta, pickFlipsResponse{Data: flips, Meta: meta})
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+    "fmt"
+    "net/http"
+    "strings"
+
+    "golang.org/x/sync/errgroup"
+)
+
+// predictionResult is the compact predicted-vs-actual row used by the
+// results-review grid.
+type predictionResult struct {
+    PredictionID      int     `json:"prediction_id"`
+    PredictedWinner   string  `json:"predicted_winner"`
+    ActualWinner      string  `json:"actual_winner"`
+    PredictionCorrect bool    `json:"prediction_correct"`
+    ConfidenceScore   int     `json:"confidence_score"`
+    PredictedOdds     float64 `json:"predicted_odds"`
+}
+
+type resultsResponse struct {
+    Data []predictionResult `json:"data"`
+    Meta responseMeta       `json:"meta"`
+}
+
+// handleListResults pages resolved predictions as compact result rows.
+// It pages by offset only: a cursor is rejected rather than ignored, since
+// these rows don't carry the sort keys a cursor resumes from.
+func (s *server) handleListResults(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+
+    page, pageSize, err := parsePagination(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    if filters.Cursor != nil {
+        respondError(w, http.StatusBadRequest, "cursor is not supported on results; page with page and pageSize")
+        return
+    }
+    filters.ResolvedOnly = true
+
+    // The count and the page share one FROM so total matches what paging
+    // walks through.
+    countQuery, countArgs := buildPredictionCountQuery(filters)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        p.prediction_id,
+        p.predicted_winner,
+        ` + actualWinnerExpr + `,
+        ` + correctExpr + `,
+        p.confidence_score,
+        ` + predictedOddsExpr + `
+        ` + scopedPredictionsFrom(filters))
+
+    clauses, args := buildWhereClauses(filters)
+    writeWhere(&base, clauses)
+    args = writeOrderAndPage(&base, filters, args, page, pageSize)
+
+    var total int
+    results := []predictionResult{}
+    g, gctx := errgroup.WithContext(ctx)
+    g.Go(func() error {
+        var err error
+        total, err = s.fetchTotal(gctx, countQuery, countArgs)
+        return err
+    })
+    g.Go(func() error {
+        rows, err := s.db.Query(gctx, base.String(), args...)
+        if err != nil {
+            return err
+        }
+        defer rows.Close()
+
+        for rows.Next() {
+            var res predictionResult
+            if err := rows.Scan(
+                &res.PredictionID,
+                &res.PredictedWinner,
+                &res.ActualWinner,
+                &res.PredictionCorrect,
+                &res.ConfidenceScore,
+                &res.PredictedOdds,
+            ); err != nil {
+                return err
+            }
+            res.PredictedOdds = roundTo(res.PredictedOdds, oddsDecimals)
+            results = append(results, res)
+        }
+        return rows.Err()
+    })
+    if err := g.Wait(); err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    meta := pageMeta(total, page, pageSize)
+    respondPage(w, meta, resultsResponse{Data: results, Meta: meta})
+}
+
+type highlightsResponse struct {
+    Best  predictionRows `json:"best"`
+    Worst predictionRows `json:"worst"`
+}
+
+// handleHighlights returns the most confident correct calls (best) and the
+// most confident misses (worst) over the last `days` days (default 30),
+// counted back from today in the caller's tz, `limit` of each (default 5,
+// max 50). Standard filters apply.
+func (s *server) handleHighlights(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    days := parseIntQuery(r, paramDays, 30)
+    if days < 1 {
+        days = 30
+    }
+    limit, err := parseIntInRange(r, paramLimit, 5, 1, 50)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    query := func(correct bool) (string, []any) {
+        clauses, args := buildWhereClauses(filters)
+        args = append(args, filters.Timezone, days)
+        clauses = append(clauses, fmt.Sprintf("p.prediction_day >= (NOW() AT TIME ZONE $%d)::date - $%d::int", len(args)-1, len(args)))
+        args = append(args, correct)
+        clauses = append(clauses, fmt.Sprintf("%s = $%d", correctExpr, len(args)))
+
+        base := strings.Builder{}
+        writePredictionSelect(&base, false)
+        writeWhere(&base, clauses)
+        args = append(args, limit)
+        base.WriteString(fmt.Sprintf(" ORDER BY p.confidence_score DESC, p.prediction_day DESC LIMIT $%d", len(args)))
+        return base.String(), args
+    }
+
+    var best, worst []prediction
+    g, ctx := errgroup.WithContext(r.Context())
+    g.Go(func() error {
+        q, args := query(true)
+        var err error
+        best, err = s.fetchPredictions(ctx, q, args)
+        return err
+    })
+    g.Go(func() error {
+        q, args := query(false)
+        var err error
+        worst, err = s.fetchPredictions(ctx, q, args)
+        return err
+    })
+    if err := g.Wait(); err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, highlightsResponse{
+        Best:  newPredictionRows(r, best),
+        Worst: newPredictionRows(r, worst),
+    })
+}
+
+// underdogPickExpr is true when the model backed the player with strictly
+// longer odds. Level odds count as neither favorite nor underdog.
+const underdogPickExpr = "(CASE WHEN p.predicted_winner = p.player1 THEN p.odds_player1 > p.odds_player2 ELSE p.odds_player2 > p.odds_player1 END)"
+
+type upsetsResponse struct {
+    Data predictionRows `json:"data"`
+}
+
+// handleUpsets lists correct calls on the underdog, longest odds first.
+// The date window comes from the usual dateFrom/dateTo/withinDays filters;
+// limit defaults to 10 (max 100).
+func (s *server) handleUpsets(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    limit, err := parseIntInRange(r, paramLimit, 10, 1, 100)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, underdogPickExpr, correctExpr)
+
+    base := strings.Builder{}
+    writePredictionSelect(&base, false)
+    writeWhere(&base, clauses)
+    args = append(args, limit)
+    base.WriteString(fmt.Sprintf(" ORDER BY %s DESC, p.prediction_day DESC LIMIT $%d", predictedOddsExpr, len(args)))
+
+    upsets, err := s.fetchPredictions(r.Context(), base.String(), args)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, upsetsResponse{Data: newPredictionRows(r, upsets)})
+}
+
+// surpriseExpr scores how wrong a resolved prediction was relative to its
+// confidence: the confidence itself for a loss, 100 minus it for a win.
+const surpriseExpr = "(CASE WHEN " + correctExpr + " THEN 100 - p.confidence_score ELSE p.confidence_score END)"
+
+type surprisesResponse struct {
+    Data predictionRows `json:"data"`
+}
+
+// handleSurprises lists resolved predictions by surpriseExpr, highest
+// first, so high-confidence losses and low-confidence wins can be reviewed
+// by hand. The date window comes from the usual filters; limit defaults to
+// 20 (max 100).
+func (s *server) handleSurprises(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    limit, err := parseIntInRange(r, paramLimit, 20, 1, 100)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    clauses, args := buildWhereClauses(filters)
+
+    base := strings.Builder{}
+    writePredictionSelect(&base, false)
+    writeWhere(&base, clauses)
+    args = append(args, limit)
+    base.WriteString(fmt.Sprintf(" ORDER BY %s DESC, p.prediction_day DESC, p.prediction_id DESC LIMIT $%d", surpriseExpr, len(args)))
+
+    surprises, err := s.fetchPredictions(r.Context(), base.String(), args)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    for i := range surprises {
+        score := surpriseScore(surprises[i])
+        surprises[i].Surprise = &score
+    }
+
+    respondJSON(w, surprisesResponse{Data: newPredictionRows(r, surprises)})
+}
+
+// surpriseScore is surpriseExpr for a scanned row, whose ActualWinner
+// already has the live result merged in.
+func surpriseScore(p prediction) int {
+    correct := p.ActualWinner != nil && *p.ActualWinner == p.PredictedWinner
+    if p.PredictionCorrect != nil {
+        correct = *p.PredictionCorrect
+    }
+    if correct {
+        return 100 - p.ConfidenceScore
+    }
+    return p.ConfidenceScore
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+    "context"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// schemaInfo records optional columns and tables found at startup, so query
+// builders can use them when present without breaking older databases.
+type schemaInfo struct {
+    // hasArchived is true once predictions has an archived soft-delete flag.
+    hasArchived bool
+    // hasUnaccent is true when the unaccent extension is installed.
+    hasUnaccent bool
+    // hasRound is true once predictions has a round column.
+    hasRound bool
+    // hasLiveMatches is true when the live_matches table exists; some
+    // deployments run without the live feed.
+    hasLiveMatches bool
+}
+
+// schema is populated once by detectSchema before the server starts.
+var schema schemaInfo
+
+func detectSchema(ctx context.Context, pool *pgxpool.Pool) error {
+    return pool.QueryRow(ctx, `SELECT
+        EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_schema = current_schema() AND table_name = 'predictions' AND column_name = 'archived'
+        ),
+        EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'unaccent'),
+        EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_schema = current_schema() AND table_name = 'predictions' AND column_name = 'round'
+        ),
+        to_regclass('live_matches') IS NOT NULL`,
+    ).Scan(&schema.hasArchived, &schema.hasUnaccent, &schema.hasRound, &schema.hasLiveMatches)
+}
+
+// Lowercase Latin letters with diacritics and their ASCII bases, for
+// folding text with translate() when unaccent isn't available.
+const (
+    accentedLetters = "áàâäãåāăąćčçďéèêëēėęěíìîïīįłńñňóòôöõøōőŕřśšşťúùûüūůűųýÿźžż"
+    accentBases     = "aaaaaaaaacccdeeeeeeeeiiiiiilnnnoooooooorrssstuuuuuuuuyyzzz"
+)
+
+// foldExpr wraps a SQL text expression so it compares case- and
+// accent-insensitively. It uses unaccent() when installed and otherwise
+// falls back to translate() over the common Latin diacritics.
+func foldExpr(expr string) string {
+    if schema.hasUnaccent {
+        return "LOWER(unaccent(" + expr + "))"
+    }
+    return "translate(LOWER(" + expr + "), '" + accentedLetters + "', '" + accentBases + "')"
+}
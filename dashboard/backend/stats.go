@@ -0,0 +1,1100 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "golang.org/x/sync/errgroup"
+)
+
+// betActionExpr matches predictions the system recommended betting on.
+const betActionExpr = "LOWER(p.recommended_action) = 'bet'"
+
+type dailyRecommendation struct {
+    Day        string `json:"day"`
+    Total      int    `json:"total"`
+    ValueBets  int    `json:"value_bets"`
+    BetActions int    `json:"bet_actions"`
+}
+
+type dailyRecommendationsResponse struct {
+    Data []dailyRecommendation `json:"data"`
+}
+
+// handleDailyRecommendations returns, per prediction_day, how many
+// predictions there were, how many were value bets and how many carried a
+// bet recommendation. Use dateFrom/dateTo to bound the range.
+func (s *server) handleDailyRecommendations(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, "p.prediction_day IS NOT NULL")
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        p.prediction_day,
+        COUNT(*),
+        COUNT(*) FILTER (WHERE p.value_bet),
+        COUNT(*) FILTER (WHERE ` + betActionExpr + `)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY p.prediction_day ORDER BY p.prediction_day")
+
+    rows, err := s.replica.Query(ctx, base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    days := []dailyRecommendation{}
+    for rows.Next() {
+        var day time.Time
+        var d dailyRecommendation
+        if err := rows.Scan(&day, &d.Total, &d.ValueBets, &d.BetActions); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        d.Day = day.Format("2006-01-02")
+        days = append(days, d)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, dailyRecommendationsResponse{Data: days})
+}
+
+type overallStats struct {
+    Total     int      `json:"total"`
+    Resolved  int      `json:"resolved"`
+    Correct   int      `json:"correct"`
+    Accuracy  *float64 `json:"accuracy"`
+    ValueBets int      `json:"value_bets"`
+}
+
+// loadOverallStats summarizes every prediction matching filters. Accuracy is
+// correct/resolved and is null until something has been resolved.
+func (s *server) loadOverallStats(ctx context.Context, filters filterSet) (overallStats, error) {
+    clauses, args := buildWhereClauses(filters)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        COUNT(*),
+        COUNT(*) FILTER (WHERE p.prediction_correct IS NOT NULL),
+        COUNT(*) FILTER (WHERE p.prediction_correct),
+        COUNT(*) FILTER (WHERE p.value_bet)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+
+    var st overallStats
+    err := s.replica.QueryRow(ctx, base.String(), args...).Scan(&st.Total, &st.Resolved, &st.Correct, &st.ValueBets)
+    if err != nil {
+        return st, err
+    }
+    if st.Resolved > 0 {
+        accuracy := roundTo(float64(st.Correct)/float64(st.Resolved), ratioDecimals)
+        st.Accuracy = &accuracy
+    }
+    return st, nil
+}
+
+// accuracyGroup is one row of an accuracy breakdown over resolved predictions.
+type accuracyGroup struct {
+    Label    string  `json:"label"`
+    Count    int     `json:"count"`
+    Correct  int     `json:"correct"`
+    Accuracy float64 `json:"accuracy"`
+}
+
+type accuracyGroupsResponse struct {
+    Data []accuracyGroup `json:"data"`
+}
+
+// accuracy is correct/count rounded for output, or 0 for an empty group.
+func accuracy(correct, count int) float64 {
+    if count == 0 {
+        return 0
+    }
+    return roundTo(float64(correct)/float64(count), ratioDecimals)
+}
+
+var weekdayLabels = [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// handleStatsByDayOfWeek groups resolved predictions by the weekday of
+// prediction_day, listed Monday first. Weekdays with no data are omitted.
+func (s *server) handleStatsByDayOfWeek(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, "p.prediction_day IS NOT NULL")
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        EXTRACT(ISODOW FROM p.prediction_day)::int AS dow,
+        COUNT(*),
+        COUNT(*) FILTER (WHERE ` + correctExpr + `)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY dow ORDER BY dow")
+
+    rows, err := s.replica.Query(ctx, base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    groups := []accuracyGroup{}
+    for rows.Next() {
+        var isoDow int
+        var g accuracyGroup
+        if err := rows.Scan(&isoDow, &g.Count, &g.Correct); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        // ISODOW runs Monday=1..Sunday=7, which gives the Mon-Sun order.
+        g.Label = weekdayLabels[isoDow%7]
+        g.Accuracy = accuracy(g.Correct, g.Count)
+        groups = append(groups, g)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, accuracyGroupsResponse{Data: groups})
+}
+
+// favoriteExpr is the player with strictly shorter odds, or NULL when the
+// odds are level and the market has no favorite.
+const favoriteExpr = "CASE WHEN p.odds_player1 < p.odds_player2 THEN p.player1 WHEN p.odds_player2 < p.odds_player1 THEN p.player2 END"
+
+type vsMarketResponse struct {
+    Resolved         int      `json:"resolved"`
+    ModelCorrect     int      `json:"model_correct"`
+    ModelAccuracy    float64  `json:"model_accuracy"`
+    FavoriteMatches  int      `json:"favorite_matches"`
+    FavoriteCorrect  int      `json:"favorite_correct"`
+    FavoriteAccuracy float64  `json:"favorite_accuracy"`
+    Lift             *float64 `json:"lift"`
+}
+
+// handleStatsVsMarket compares the model's hit rate with an always-back-the-
+// favorite baseline over the same resolved predictions. Matches with level
+// odds have no favorite and only count toward the model's side.
+func (s *server) handleStatsVsMarket(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    clauses, args := buildWhereClauses(filters)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        COUNT(*),
+        COUNT(*) FILTER (WHERE ` + correctExpr + `),
+        COUNT(*) FILTER (WHERE p.odds_player1 <> p.odds_player2),
+        COUNT(*) FILTER (WHERE (` + favoriteExpr + `) = ` + actualWinnerExpr + `)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+
+    var resp vsMarketResponse
+    err = s.replica.QueryRow(r.Context(), base.String(), args...).Scan(
+        &resp.Resolved, &resp.ModelCorrect, &resp.FavoriteMatches, &resp.FavoriteCorrect)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    resp.ModelAccuracy = accuracy(resp.ModelCorrect, resp.Resolved)
+    resp.FavoriteAccuracy = accuracy(resp.FavoriteCorrect, resp.FavoriteMatches)
+    if resp.Resolved > 0 && resp.FavoriteMatches > 0 {
+        lift := roundTo(resp.ModelAccuracy-resp.FavoriteAccuracy, ratioDecimals)
+        resp.Lift = &lift
+    }
+
+    respondJSON(w, resp)
+}
+
+type positionPicks struct {
+    Count    int     `json:"count"`
+    Correct  int     `json:"correct"`
+    Accuracy float64 `json:"accuracy"`
+    Share    float64 `json:"share"`
+}
+
+type positionBiasResponse struct {
+    Resolved  int           `json:"resolved"`
+    Player1   positionPicks `json:"player1"`
+    Player2   positionPicks `json:"player2"`
+    Unmatched int           `json:"unmatched"`
+}
+
+// handleStatsPositionBias splits resolved predictions by whether the pick
+// was player1 or player2, with each side's share and accuracy. A heavy skew
+// toward one slot points at an ordering artifact in the input data. Picks
+// that match neither name are counted as unmatched.
+func (s *server) handleStatsPositionBias(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    clauses, args := buildWhereClauses(filters)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        COUNT(*),
+        COUNT(*) FILTER (WHERE p.predicted_winner = p.player1),
+        COUNT(*) FILTER (WHERE p.predicted_winner = p.player1 AND ` + correctExpr + `),
+        COUNT(*) FILTER (WHERE p.predicted_winner = p.player2 AND p.predicted_winner <> p.player1),
+        COUNT(*) FILTER (WHERE p.predicted_winner = p.player2 AND p.predicted_winner <> p.player1 AND ` + correctExpr + `)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+
+    var resp positionBiasResponse
+    err = s.replica.QueryRow(r.Context(), base.String(), args...).Scan(
+        &resp.Resolved, &resp.Player1.Count, &resp.Player1.Correct, &resp.Player2.Count, &resp.Player2.Correct)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    resp.Unmatched = resp.Resolved - resp.Player1.Count - resp.Player2.Count
+    for _, side := range []*positionPicks{&resp.Player1, &resp.Player2} {
+        side.Accuracy = accuracy(side.Correct, side.Count)
+        side.Share = accuracy(side.Count, resp.Resolved)
+    }
+
+    respondJSON(w, resp)
+}
+
+type windowStats struct {
+    From        string   `json:"from"`
+    To          string   `json:"to"`
+    Predictions int      `json:"predictions"`
+    Resolved    int      `json:"resolved"`
+    Correct     int      `json:"correct"`
+    Accuracy    *float64 `json:"accuracy"`
+    // PricedBets is the resolved picks with valid odds, which ROI covers.
+    PricedBets  int      `json:"priced_bets"`
+    ROI         *float64 `json:"roi"`
+}
+
+type windowDelta struct {
+    Predictions int      `json:"predictions"`
+    Resolved    int      `json:"resolved"`
+    Accuracy    *float64 `json:"accuracy"`
+    ROI         *float64 `json:"roi"`
+}
+
+type compareResponse struct {
+    A     windowStats `json:"a"`
+    B     windowStats `json:"b"`
+    Delta windowDelta `json:"delta"`
+}
+
+// handleStatsCompare aggregates two prediction_day windows, A (aFrom-aTo)
+// and B (bFrom-bTo), under the same other filters, with A minus B as the
+// delta. ROI is for a unit stake on every resolved pick at the predicted
+// winner's odds, leaving out picks with invalid odds (counted in
+// priced_bets); accuracy still covers every resolved pick. All four dates
+// are required, inclusive, as YYYY-MM-DD.
+func (s *server) handleStatsCompare(w http.ResponseWriter, r *http.Request) {
+    var bounds [4]time.Time
+    var problems filterErrors
+    for i, key := range []queryParam{paramAFrom, paramATo, paramBFrom, paramBTo} {
+        t, err := time.Parse("2006-01-02", strings.TrimSpace(key.get(r)))
+        if err != nil {
+            problems = append(problems, fmt.Sprintf("%s must be a YYYY-MM-DD date", key))
+            continue
+        }
+        bounds[i] = t
+    }
+    if len(problems) == 0 {
+        if bounds[1].Before(bounds[0]) {
+            problems = append(problems, "aTo is before aFrom")
+        }
+        if bounds[3].Before(bounds[2]) {
+            problems = append(problems, "bTo is before bFrom")
+        }
+    }
+    if len(problems) > 0 {
+        respondError(w, http.StatusBadRequest, problems.Error())
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    var resp compareResponse
+    g, gctx := errgroup.WithContext(r.Context())
+    for i, out := range []*windowStats{&resp.A, &resp.B} {
+        windowFilters := filters
+        windowFilters.DateFrom = &bounds[2*i]
+        windowFilters.DateTo = &bounds[2*i+1]
+        g.Go(func() error {
+            stats, err := s.loadWindowStats(gctx, windowFilters)
+            *out = stats
+            return err
+        })
+    }
+    if err := g.Wait(); err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+
+    resp.Delta = windowDelta{
+        Predictions: resp.A.Predictions - resp.B.Predictions,
+        Resolved:    resp.A.Resolved - resp.B.Resolved,
+        Accuracy:    ptrDelta(resp.A.Accuracy, resp.B.Accuracy),
+        ROI:         ptrDelta(resp.A.ROI, resp.B.ROI),
+    }
+
+    respondJSON(w, resp)
+}
+
+// loadWindowStats aggregates the predictions matching filters for
+// handleStatsCompare. filters must set DateFrom and DateTo.
+func (s *server) loadWindowStats(ctx context.Context, filters filterSet) (windowStats, error) {
+    clauses, args := buildWhereClauses(filters)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        COUNT(*),
+        COUNT(*) FILTER (WHERE ` + actualWinnerExpr + ` IS NOT NULL),
+        COUNT(*) FILTER (WHERE ` + actualWinnerExpr + ` IS NOT NULL AND ` + correctExpr + `),
+        ` + unitStakeColumns() + `
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+
+    st := windowStats{From: filters.DateFrom.Format("2006-01-02"), To: filters.DateTo.Format("2006-01-02")}
+    var priced unitStakeReturn
+    if err := s.replica.QueryRow(ctx, base.String(), args...).Scan(
+        &st.Predictions, &st.Resolved, &st.Correct, &priced.Won, &priced.Lost, &priced.Winnings); err != nil {
+        return st, err
+    }
+    if st.Resolved > 0 {
+        a := accuracy(st.Correct, st.Resolved)
+        st.Accuracy = &a
+    }
+    st.PricedBets = priced.bets()
+    st.ROI = priced.roi()
+    return st, nil
+}
+
+// ptrDelta is a minus b, or nil when either is missing.
+func ptrDelta(a, b *float64) *float64 {
+    if a == nil || b == nil {
+        return nil
+    }
+    d := roundTo(*a-*b, ratioDecimals)
+    return &d
+}
+
+type actionShare struct {
+    Action  string  `json:"action"`
+    Count   int     `json:"count"`
+    Percent float64 `json:"percent"`
+}
+
+type actionSharesResponse struct {
+    Data  []actionShare `json:"data"`
+    Total int           `json:"total"`
+}
+
+// handleStatsActions breaks the filtered predictions down by
+// recommended_action, most common first. Rows with no action are left out
+// of both the groups and the total the percentages are taken from.
+func (s *server) handleStatsActions(w http.ResponseWriter, r *http.Request) {
+    ctx := r.Context()
+
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, "NULLIF(p.recommended_action, '') IS NOT NULL")
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT p.recommended_action, COUNT(*) ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY p.recommended_action ORDER BY COUNT(*) DESC, p.recommended_action")
+
+    rows, err := s.replica.Query(ctx, base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    resp := actionSharesResponse{Data: []actionShare{}}
+    for rows.Next() {
+        var a actionShare
+        if err := rows.Scan(&a.Action, &a.Count); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        resp.Total += a.Count
+        resp.Data = append(resp.Data, a)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+    for i := range resp.Data {
+        resp.Data[i].Percent = roundTo(100*float64(resp.Data[i].Count)/float64(resp.Total), 2)
+    }
+
+    respondJSON(w, resp)
+}
+
+type surfaceSummary struct {
+    Surface  string  `json:"surface"`
+    Label    *string `json:"label,omitempty"`
+    Count    int     `json:"count"`
+    Resolved int     `json:"resolved"`
+    Correct  int     `json:"correct"`
+    Accuracy float64 `json:"accuracy"`
+}
+
+type surfacesResponse struct {
+    Data []surfaceSummary `json:"data"`
+}
+
+// handleSurfaces lists each non-empty surface with its prediction count,
+// resolved count and accuracy, most predictions first, for a surface picker
+// that shows performance next to each option. The usual filters apply, and
+// labels are added when a locale is negotiated.
+func (s *server) handleSurfaces(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, "NULLIF(TRIM(p.surface), '') IS NOT NULL")
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        p.surface,
+        COUNT(*),
+        COUNT(*) FILTER (WHERE ` + actualWinnerExpr + ` IS NOT NULL),
+        COUNT(*) FILTER (WHERE ` + correctExpr + `)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY p.surface ORDER BY COUNT(*) DESC, p.surface")
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    if len(s.labels) > 0 {
+        w.Header().Add("Vary", "Accept-Language")
+    }
+    locale := s.labels.locale(r)
+    resp := surfacesResponse{Data: []surfaceSummary{}}
+    for rows.Next() {
+        var sf surfaceSummary
+        if err := rows.Scan(&sf.Surface, &sf.Count, &sf.Resolved, &sf.Correct); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        sf.Accuracy = accuracy(sf.Correct, sf.Resolved)
+        if locale != "" {
+            label := s.labels.label(locale, sf.Surface)
+            sf.Label = &label
+        }
+        resp.Data = append(resp.Data, sf)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, resp)
+}
+
+// maxComparedTournaments caps how many names one comparison request may ask for.
+const maxComparedTournaments = 50
+
+type tournamentStats struct {
+    Tournament    string   `json:"tournament"`
+    Count         int      `json:"count"`
+    Resolved      int      `json:"resolved"`
+    Correct       int      `json:"correct"`
+    Accuracy      float64  `json:"accuracy"`
+    AvgConfidence *float64 `json:"avg_confidence"`
+}
+
+type tournamentStatsResponse struct {
+    Data []tournamentStats `json:"data"`
+}
+
+// handleStatsTournaments aggregates the filtered predictions for each
+// tournament in names, returned in the order requested. Names without data
+// still get a zeroed entry so comparison tables line up.
+func (s *server) handleStatsTournaments(w http.ResponseWriter, r *http.Request) {
+    names := splitCSV(paramNames.get(r))
+    if len(names) == 0 {
+        respondError(w, http.StatusBadRequest, "names is required")
+        return
+    }
+    if len(names) > maxComparedTournaments {
+        respondError(w, http.StatusBadRequest, fmt.Sprintf("at most %d names per request", maxComparedTournaments))
+        return
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    clauses, args := buildWhereClauses(filters)
+    args = append(args, names)
+    clauses = append(clauses, fmt.Sprintf("p.tournament = ANY($%d)", len(args)))
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        p.tournament,
+        COUNT(*),
+        COUNT(*) FILTER (WHERE ` + actualWinnerExpr + ` IS NOT NULL),
+        COUNT(*) FILTER (WHERE ` + correctExpr + `),
+        AVG(p.confidence_score)::float8
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY p.tournament")
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    found := make(map[string]tournamentStats, len(names))
+    for rows.Next() {
+        var t tournamentStats
+        if err := rows.Scan(&t.Tournament, &t.Count, &t.Resolved, &t.Correct, &t.AvgConfidence); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        t.Accuracy = accuracy(t.Correct, t.Resolved)
+        t.AvgConfidence = roundPtr(t.AvgConfidence, 2)
+        found[t.Tournament] = t
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    resp := tournamentStatsResponse{Data: make([]tournamentStats, 0, len(names))}
+    seen := make(map[string]bool, len(names))
+    for _, name := range names {
+        if seen[name] {
+            continue
+        }
+        seen[name] = true
+        t, ok := found[name]
+        if !ok {
+            t = tournamentStats{Tournament: name}
+        }
+        resp.Data = append(resp.Data, t)
+    }
+
+    respondJSON(w, resp)
+}
+
+type rollingAccuracyResponse struct {
+    Window   int        `json:"window"`
+    Count    int        `json:"count"`
+    Correct  int        `json:"correct"`
+    Accuracy *float64   `json:"accuracy"`
+    FirstDay *time.Time `json:"first_day"`
+    LastDay  *time.Time `json:"last_day"`
+}
+
+// handleStatsRolling reports accuracy over the most recent window resolved
+// predictions by created_at, with the prediction_day span they cover.
+// window defaults to 50 and must be 1-1000. Count falls short of window
+// when there isn't enough history.
+func (s *server) handleStatsRolling(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    window, err := parseIntInRange(r, paramWindow, 50, 1, 1000)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    clauses, args := buildWhereClauses(filters)
+    inner := strings.Builder{}
+    inner.WriteString(`SELECT ` + correctExpr + ` AS correct, p.prediction_day ` + predictionsFrom)
+    writeWhere(&inner, clauses)
+    args = append(args, window)
+    inner.WriteString(fmt.Sprintf(" ORDER BY p.created_at DESC, p.prediction_id DESC LIMIT $%d", len(args)))
+
+    query := `SELECT COUNT(*), COUNT(*) FILTER (WHERE correct), MIN(prediction_day), MAX(prediction_day)
+        FROM (` + inner.String() + `) recent`
+
+    resp := rollingAccuracyResponse{Window: window}
+    err = s.replica.QueryRow(r.Context(), query, args...).Scan(&resp.Count, &resp.Correct, &resp.FirstDay, &resp.LastDay)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    if resp.Count > 0 {
+        a := accuracy(resp.Correct, resp.Count)
+        resp.Accuracy = &a
+    }
+
+    respondJSON(w, resp)
+}
+
+// expectedValue is the expected profit per unit staked on the predicted
+// winner at decimal odds, taking confidence (0-100) as the win probability:
+// p*(odds-1) - (1-p). ok is false when odds aren't valid decimal odds (over
+// 1), as there is no meaningful EV then. evExpr is the same formula in SQL.
+func expectedValue(confidence int, odds float64) (ev float64, ok bool) {
+    if !(odds > 1) {
+        return 0, false
+    }
+    p := float64(confidence) / 100
+    return p*(odds-1) - (1 - p), true
+}
+
+// evExpr computes expectedValue for a predictions row; it is NULL when the
+// predicted winner's odds are NULL or not over 1.
+const evExpr = "(CASE WHEN (" + predictedOddsExpr + ") > 1 THEN ((p.confidence_score / 100.0) * ((" + predictedOddsExpr + ") - 1) - (1 - p.confidence_score / 100.0))::float8 END)"
+
+type evStatsResponse struct {
+    Count    int      `json:"count"`
+    Positive int      `json:"positive"`
+    AvgEV    *float64 `json:"avg_ev"`
+    MedianEV *float64 `json:"median_ev"`
+    MinEV    *float64 `json:"min_ev"`
+    MaxEV    *float64 `json:"max_ev"`
+}
+
+// handleStatsEV summarizes expected value across the filtered predictions
+// the system recommended betting on. Bets without valid odds have no EV and
+// are left out; the EV figures are null when no bets remain.
+func (s *server) handleStatsEV(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, betActionExpr)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        COUNT(` + evExpr + `),
+        COUNT(*) FILTER (WHERE ` + evExpr + ` > 0),
+        AVG(` + evExpr + `),
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY ` + evExpr + `),
+        MIN(` + evExpr + `),
+        MAX(` + evExpr + `)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+
+    var resp evStatsResponse
+    err = s.replica.QueryRow(r.Context(), base.String(), args...).Scan(
+        &resp.Count, &resp.Positive, &resp.AvgEV, &resp.MedianEV, &resp.MinEV, &resp.MaxEV)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    resp.AvgEV = roundPtr(resp.AvgEV, ratioDecimals)
+    resp.MedianEV = roundPtr(resp.MedianEV, ratioDecimals)
+    resp.MinEV = roundPtr(resp.MinEV, ratioDecimals)
+    resp.MaxEV = roundPtr(resp.MaxEV, ratioDecimals)
+
+    respondJSON(w, resp)
+}
+
+type calendarDay struct {
+    Date  string `json:"date"`
+    Count int    `json:"count"`
+}
+
+type calendarResponse struct {
+    Year int           `json:"year"`
+    Data []calendarDay `json:"data"`
+}
+
+// handleStatsCalendar counts predictions per prediction_day in year
+// (default the current one) for a heatmap. Only days with predictions are
+// listed; the client fills the gaps with zero.
+func (s *server) handleStatsCalendar(w http.ResponseWriter, r *http.Request) {
+    year := time.Now().Year()
+    if v := strings.TrimSpace(paramYear.get(r)); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1900 || n > 9999 {
+            respondError(w, http.StatusBadRequest, "year must be a four-digit year")
+            return
+        }
+        year = n
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    clauses, args := buildWhereClauses(filters)
+    args = append(args, year)
+    clauses = append(clauses, fmt.Sprintf("p.prediction_day >= make_date($%d, 1, 1) AND p.prediction_day < make_date($%d + 1, 1, 1)", len(args), len(args)))
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT p.prediction_day, COUNT(*) ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY p.prediction_day ORDER BY p.prediction_day")
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    resp := calendarResponse{Year: year, Data: []calendarDay{}}
+    for rows.Next() {
+        var day time.Time
+        var d calendarDay
+        if err := rows.Scan(&day, &d.Count); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        d.Date = day.Format("2006-01-02")
+        resp.Data = append(resp.Data, d)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    respondJSON(w, resp)
+}
+
+type timeseriesPoint struct {
+    Date          string   `json:"date"`
+    Count         int      `json:"count"`
+    Correct       int      `json:"correct"`
+    Accuracy      float64  `json:"accuracy"`
+    MovingAverage *float64 `json:"moving_average,omitempty"`
+}
+
+type timeseriesResponse struct {
+    MA   int               `json:"ma,omitempty"`
+    Data []timeseriesPoint `json:"data"`
+}
+
+// handleStatsTimeseries reports daily accuracy of resolved predictions by
+// prediction_day, oldest first. ma=N (1-365) adds a trailing N-day moving
+// average of the daily accuracy; days inside the window that have no
+// resolved predictions don't count, so the warm-up averages what's there.
+func (s *server) handleStatsTimeseries(w http.ResponseWriter, r *http.Request) {
+    ma := 0
+    if v := strings.TrimSpace(paramMa.get(r)); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > 365 {
+            respondError(w, http.StatusBadRequest, "ma must be a number of days from 1 to 365")
+            return
+        }
+        ma = n
+    }
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, "p.prediction_day IS NOT NULL")
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT p.prediction_day, COUNT(*), COUNT(*) FILTER (WHERE ` + correctExpr + `) ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY p.prediction_day ORDER BY p.prediction_day")
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    var days []time.Time
+    resp := timeseriesResponse{MA: ma, Data: []timeseriesPoint{}}
+    for rows.Next() {
+        var day time.Time
+        var pt timeseriesPoint
+        if err := rows.Scan(&day, &pt.Count, &pt.Correct); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        pt.Date = day.Format("2006-01-02")
+        pt.Accuracy = accuracy(pt.Correct, pt.Count)
+        days = append(days, day)
+        resp.Data = append(resp.Data, pt)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    if ma > 0 {
+        movingAverage(resp.Data, days, ma)
+    }
+
+    respondJSON(w, resp)
+}
+
+// movingAverage sets each point's MovingAverage to the mean daily accuracy
+// over the points from the last n calendar days up to and including it.
+// points and days are parallel and ordered by day.
+func movingAverage(points []timeseriesPoint, days []time.Time, n int) {
+    start, sum := 0, 0.0
+    for i := range points {
+        sum += points[i].Accuracy
+        for !days[start].After(days[i].AddDate(0, 0, -n)) {
+            sum -= points[start].Accuracy
+            start++
+        }
+        avg := roundTo(sum/float64(i-start+1), ratioDecimals)
+        points[i].MovingAverage = &avg
+    }
+}
+
+// Patterns for guessing a match's round from free text. Separators are any
+// non-alphanumeric character, so "_QF_" in a match_id counts as a word.
+const (
+    roundText   = "LOWER(p.tournament || ' ' || p.match_id)"
+    roundBefore = "(?:^|[^a-z0-9])"
+    roundAfter  = "(?:[^a-z0-9]|$)"
+)
+
+// roundGuessExpr reads the round out of the tournament name and match_id,
+// best effort: "qualifying"/Q1-Q3, "round of 32"/R32, QF, SF and "final".
+// "Finals" (plural) is skipped since it names events like the ATP Finals.
+// Anything else is NULL.
+const roundGuessExpr = `CASE
+    WHEN ` + roundText + ` ~ 'qualif' OR ` + roundText + ` ~ '` + roundBefore + `q[1-3]` + roundAfter + `' THEN 'Q'
+    WHEN ` + roundText + ` ~ 'quarter[ _-]?finals?' OR ` + roundText + ` ~ '` + roundBefore + `qf` + roundAfter + `' THEN 'QF'
+    WHEN ` + roundText + ` ~ 'semi[ _-]?finals?' OR ` + roundText + ` ~ '` + roundBefore + `sf` + roundAfter + `' THEN 'SF'
+    WHEN ` + roundText + ` ~ '` + roundBefore + `final` + roundAfter + `' THEN 'F'
+    WHEN ` + roundText + ` ~ '` + roundBefore + `r(128|64|32|16)` + roundAfter + `'
+        THEN 'R' || substring(` + roundText + ` from '` + roundBefore + `r(128|64|32|16)` + roundAfter + `')
+    WHEN ` + roundText + ` ~ 'round[ _-]of[ _-](128|64|32|16)'
+        THEN 'R' || substring(` + roundText + ` from 'round[ _-]of[ _-](128|64|32|16)')
+END`
+
+// roundExpr is the round column when the schema has one, with the text
+// heuristic filling rows where it is empty.
+func roundExpr() string {
+    if schema.hasRound {
+        return "COALESCE(NULLIF(UPPER(p.round), ''), " + roundGuessExpr + ")"
+    }
+    return roundGuessExpr
+}
+
+// roundOrder lists rounds from earliest to latest; unknown rounds sort last.
+var roundOrder = map[string]int{"Q": 0, "R128": 1, "R64": 2, "R32": 3, "R16": 4, "QF": 5, "SF": 6, "F": 7}
+
+// handleStatsByRound groups resolved predictions by round, earliest first.
+// Rounds come from roundExpr; rows it can't place are grouped as "unknown".
+func (s *server) handleStatsByRound(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    clauses, args := buildWhereClauses(filters)
+
+    base := strings.Builder{}
+    base.WriteString(`SELECT
+        COALESCE(` + roundExpr() + `, 'unknown') AS round_label,
+        COUNT(*),
+        COUNT(*) FILTER (WHERE ` + correctExpr + `)
+        ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY round_label")
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    groups := []accuracyGroup{}
+    for rows.Next() {
+        var g accuracyGroup
+        if err := rows.Scan(&g.Label, &g.Count, &g.Correct); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        g.Accuracy = accuracy(g.Correct, g.Count)
+        groups = append(groups, g)
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    rank := func(label string) int {
+        if i, ok := roundOrder[label]; ok {
+            return i
+        }
+        return len(roundOrder)
+    }
+    sort.SliceStable(groups, func(i, j int) bool {
+        if rank(groups[i].Label) != rank(groups[j].Label) {
+            return rank(groups[i].Label) < rank(groups[j].Label)
+        }
+        return groups[i].Label < groups[j].Label
+    })
+
+    respondJSON(w, accuracyGroupsResponse{Data: groups})
+}
+
+// qualityBandWidth is the width of each data_quality_score band.
+const qualityBandWidth = 10
+
+type qualityAccuracyResponse struct {
+    Data        []accuracyGroup `json:"data"`
+    Pairs       int             `json:"pairs"`
+    Correlation *float64        `json:"correlation"`
+}
+
+// pearson accumulates a weighted Pearson correlation over (x, y) pairs.
+type pearson struct {
+    n, sx, sy, sxx, syy, sxy float64
+}
+
+func (p *pearson) add(x, y float64, weight int) {
+    w := float64(weight)
+    p.n += w
+    p.sx += w * x
+    p.sy += w * y
+    p.sxx += w * x * x
+    p.syy += w * y * y
+    p.sxy += w * x * y
+}
+
+// coefficient is nil when either variable is constant or there is no data.
+func (p *pearson) coefficient() *float64 {
+    cov := p.n*p.sxy - p.sx*p.sy
+    vx := p.n*p.sxx - p.sx*p.sx
+    vy := p.n*p.syy - p.sy*p.sy
+    if p.n < 2 || vx <= 0 || vy <= 0 {
+        return nil
+    }
+    r := roundTo(cov/math.Sqrt(vx*vy), ratioDecimals)
+    return &r
+}
+
+// handleStatsQualityVsAccuracy groups resolved predictions into
+// data_quality_score bands of 10 with their accuracy, and reports the
+// Pearson correlation between the score and being correct (1/0). Rows
+// without a quality score are left out.
+func (s *server) handleStatsQualityVsAccuracy(w http.ResponseWriter, r *http.Request) {
+    filters, err := collectFilters(r)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    filters.ResolvedOnly = true
+    clauses, args := buildWhereClauses(filters)
+    clauses = append(clauses, "p.data_quality_score IS NOT NULL")
+
+    // Grouping by score and outcome keeps the result small; the correlation
+    // is exact because every pair in a group is identical.
+    base := strings.Builder{}
+    base.WriteString(`SELECT p.data_quality_score, ` + correctExpr + `, COUNT(*) ` + predictionsFrom)
+    writeWhere(&base, clauses)
+    base.WriteString(" GROUP BY 1, 2")
+
+    rows, err := s.replica.Query(r.Context(), base.String(), args...)
+    if err != nil {
+        httpError(w, err, http.StatusInternalServerError)
+        return
+    }
+    defer rows.Close()
+
+    var corr pearson
+    bands := map[int]*accuracyGroup{}
+    for rows.Next() {
+        var quality, count int
+        var correct bool
+        if err := rows.Scan(&quality, &correct, &count); err != nil {
+            httpError(w, err, http.StatusInternalServerError)
+            return
+        }
+        y := 0.0
+        if correct {
+            y = 1
+        }
+        corr.add(float64(quality), y, count)
+
+        band := quality / qualityBandWidth * qualityBandWidth
+        g, ok := bands[band]
+        if !ok {
+            g = &accuracyGroup{Label: fmt.Sprintf("%d-%d", band, band+qualityBandWidth-1)}
+            bands[band] = g
+        }
+        g.Count += count
+        if correct {
+            g.Correct += count
+        }
+    }
+    if rows.Err() != nil {
+        httpError(w, rows.Err(), http.StatusInternalServerError)
+        return
+    }
+
+    order := make([]int, 0, len(bands))
+    for band := range bands {
+        order = append(order, band)
+    }
+    sort.Ints(order)
+    resp := qualityAccuracyResponse{Data: make([]accuracyGroup, 0, len(order)), Pairs: int(corr.n), Correlation: corr.coefficient()}
+    for _, band := range order {
+        g := bands[band]
+        g.Accuracy = accuracy(g.Correct, g.Count)
+        resp.Data = append(resp.Data, *g)
+    }
+
+    respondJSON(w, resp)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+    "math"
+    "strings"
+    "testing"
+)
+
+func TestExpectedValue(t *testing.T) {
+    tests := []struct {
+        name       string
+        confidence int
+        odds       float64
+        want       float64
+        ok         bool
+    }{
+        {"fair coin at evens", 50, 2, 0, true},
+        {"edge at evens", 60, 2, 0.2, true},
+        {"underdog value", 40, 3, 0.2, true},
+        {"short favorite", 80, 1.2, -0.04, true},
+        {"no chance", 0, 5, -1, true},
+        {"certain", 100, 1.5, 0.5, true},
+        {"odds of 1", 70, 1, 0, false},
+        {"odds below 1", 70, 0.8, 0, false},
+        {"missing odds", 70, 0, 0, false},
+        {"negative odds", 70, -1.5, 0, false},
+        {"NaN odds", 70, math.NaN(), 0, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, ok := expectedValue(tt.confidence, tt.odds)
+            if ok != tt.ok {
+                t.Fatalf("expectedValue(%d, %v) ok = %v, want %v", tt.confidence, tt.odds, ok, tt.ok)
+            }
+            if math.Abs(got-tt.want) > 1e-9 {
+                t.Errorf("expectedValue(%d, %v) = %v, want %v", tt.confidence, tt.odds, got, tt.want)
+            }
+        })
+    }
+}
+
+// evExpr must leave rows without valid odds NULL, like expectedValue, so
+// aggregates skip them rather than counting a -1 EV.
+func TestEVExprGuardsInvalidOdds(t *testing.T) {
+    if !strings.HasPrefix(evExpr, "(CASE WHEN ("+predictedOddsExpr+") > 1 THEN ") {
+        t.Errorf("evExpr does not guard on odds > 1: %s", evExpr)
+    }
+    if outer := strings.ReplaceAll(evExpr, predictedOddsExpr, "odds"); strings.Contains(outer, " ELSE ") {
+        t.Errorf("evExpr has a fallback value for invalid odds: %s", outer)
+    }
+}
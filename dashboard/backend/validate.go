@@ -0,0 +1,79 @@
+package main
+
+import (
+    "errors"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// filterErrors lists every problem found in a request's filter params so the
+// caller can report them all at once.
+type filterErrors []string
+
+func (e filterErrors) Error() string {
+    return strings.Join(e, "; ")
+}
+
+// unknownQueryParams returns the sorted params on r that aren't declared
+// with newQueryParam, when the caller asked for strict=true.
+func unknownQueryParams(r *http.Request) []string {
+    query := r.URL.Query()
+    if strict, err := strconv.ParseBool(strings.TrimSpace(paramStrict.get(r))); err != nil || !strict {
+        return nil
+    }
+    var unknown []string
+    for key := range query {
+        if _, ok := knownQueryParams[key]; !ok {
+            unknown = append(unknown, key)
+        }
+    }
+    sort.Strings(unknown)
+    return unknown
+}
+
+// validateFilters checks combinations that parse fine individually but
+// can't match anything or contradict each other.
+func validateFilters(f filterSet) []string {
+    var problems []string
+    if f.DateFrom != nil && f.DateTo != nil && f.DateFrom.After(*f.DateTo) {
+        problems = append(problems, "dateFrom must not be after dateTo")
+    }
+    if f.ResolvedFrom != nil && f.ResolvedTo != nil && f.ResolvedFrom.After(*f.ResolvedTo) {
+        problems = append(problems, "resolvedFrom must not be after resolvedTo")
+    }
+    if f.MinConfidence != nil && f.MaxConfidence != nil && *f.MinConfidence > *f.MaxConfidence {
+        problems = append(problems, "minConfidence must not exceed maxConfidence")
+    }
+    if f.MinOddsSpread != nil && f.MaxOddsSpread != nil && *f.MinOddsSpread > *f.MaxOddsSpread {
+        problems = append(problems, "minOddsSpread must not exceed maxOddsSpread")
+    }
+    if f.WithinDays != nil && (f.DateFrom != nil || f.DateTo != nil) {
+        problems = append(problems, "withinDays cannot be combined with dateFrom/dateTo")
+    }
+    if f.Seed != "" && f.SortBy != "random" {
+        problems = append(problems, "seed only applies to sortBy=random")
+    }
+    return problems
+}
+
+type validationResponse struct {
+    Valid  bool     `json:"valid"`
+    Errors []string `json:"errors"`
+}
+
+// handleValidateFilters runs the same parsing and validation as the real
+// endpoints without touching the database.
+func handleValidateFilters(w http.ResponseWriter, r *http.Request) {
+    resp := validationResponse{Valid: true, Errors: []string{}}
+    if _, err := collectFilters(r); err != nil {
+        var problems filterErrors
+        if !errors.As(err, &problems) {
+            problems = filterErrors{err.Error()}
+        }
+        resp.Valid = false
+        resp.Errors = problems
+    }
+    respondJSON(w, resp)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+    "go/ast"
+    "go/parser"
+    "go/token"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// parsePackage parses the package's non-test sources.
+func parsePackage(t *testing.T) map[string]*ast.File {
+    t.Helper()
+    paths, err := filepath.Glob("*.go")
+    if err != nil {
+        t.Fatal(err)
+    }
+    fset := token.NewFileSet()
+    files := map[string]*ast.File{}
+    for _, path := range paths {
+        if strings.HasSuffix(path, "_test.go") {
+            continue
+        }
+        src, err := os.ReadFile(path)
+        if err != nil {
+            t.Fatal(err)
+        }
+        f, err := parser.ParseFile(fset, path, src, 0)
+        if err != nil {
+            t.Fatal(err)
+        }
+        files[path] = f
+    }
+    return files
+}
+
+// declaredParams maps each newQueryParam variable to the param name it
+// declares.
+func declaredParams(t *testing.T, files map[string]*ast.File) map[string]string {
+    t.Helper()
+    params := map[string]string{}
+    for _, f := range files {
+        ast.Inspect(f, func(n ast.Node) bool {
+            spec, ok := n.(*ast.ValueSpec)
+            if !ok || len(spec.Names) != 1 || len(spec.Values) != 1 {
+                return true
+            }
+            call, ok := spec.Values[0].(*ast.CallExpr)
+            if !ok || len(call.Args) != 1 {
+                return true
+            }
+            if fn, ok := call.Fun.(*ast.Ident); !ok || fn.Name != "newQueryParam" {
+                return true
+            }
+            lit, ok := call.Args[0].(*ast.BasicLit)
+            if !ok {
+                t.Fatalf("%s is not declared with a literal name", spec.Names[0].Name)
+            }
+            name, err := strconv.Unquote(lit.Value)
+            if err != nil {
+                t.Fatal(err)
+            }
+            params[spec.Names[0].Name] = name
+            return true
+        })
+    }
+    if len(params) == 0 {
+        t.Fatal("no newQueryParam declarations found")
+    }
+    return params
+}
+
+// funcName is a declaration's name, with its receiver type for methods.
+func funcName(fn *ast.FuncDecl) string {
+    if fn.Recv == nil || len(fn.Recv.List) == 0 {
+        return fn.Name.Name
+    }
+    recv := fn.Recv.List[0].Type
+    if star, ok := recv.(*ast.StarExpr); ok {
+        recv = star.X
+    }
+    if ident, ok := recv.(*ast.Ident); ok {
+        return ident.Name + "." + fn.Name.Name
+    }
+    return fn.Name.Name
+}
+
+// Params must be read through queryParam.get so every one is declared.
+// Only the registry itself, strict mode and preset expansion, which handle
+// the query as a whole, may touch URL.Query directly.
+func TestQueryParamsReadThroughRegistry(t *testing.T) {
+    allowed := map[string]bool{
+        "queryParam.get":       true,
+        "unknownQueryParams":   true,
+        "server.expandPresets": true,
+    }
+    for path, f := range parsePackage(t) {
+        for _, decl := range f.Decls {
+            fn, ok := decl.(*ast.FuncDecl)
+            if !ok || fn.Body == nil || allowed[funcName(fn)] {
+                continue
+            }
+            ast.Inspect(fn.Body, func(n ast.Node) bool {
+                sel, ok := n.(*ast.SelectorExpr)
+                if !ok {
+                    return true
+                }
+                onURL := false
+                if inner, ok := sel.X.(*ast.SelectorExpr); ok && inner.Sel.Name == "URL" {
+                    onURL = true
+                }
+                if onURL && sel.Sel.Name == "Query" || sel.Sel.Name == "FormValue" {
+                    t.Errorf("%s: %s reads the query directly (%s); declare a queryParam instead", path, funcName(fn), sel.Sel.Name)
+                }
+                return true
+            })
+        }
+    }
+}
+
+// Every declared param is read somewhere, so strict mode doesn't accept a
+// param nothing uses.
+func TestDeclaredQueryParamsAreRead(t *testing.T) {
+    files := parsePackage(t)
+    used := map[string]bool{}
+    for path, f := range files {
+        if path == "params.go" {
+            continue
+        }
+        ast.Inspect(f, func(n ast.Node) bool {
+            if ident, ok := n.(*ast.Ident); ok {
+                used[ident.Name] = true
+            }
+            return true
+        })
+    }
+    for ident, name := range declaredParams(t, files) {
+        if !used[ident] {
+            t.Errorf("query param %q (%s) is declared but never read", name, ident)
+        }
+    }
+}
+
+// Every param collectFilters reads passes strict mode.
+func TestStrictAcceptsCollectFiltersParams(t *testing.T) {
+    files := parsePackage(t)
+    params := declaredParams(t, files)
+
+    read := map[string]string{}
+    for _, f := range files {
+        for _, decl := range f.Decls {
+            fn, ok := decl.(*ast.FuncDecl)
+            if !ok || fn.Name.Name != "collectFilters" {
+                continue
+            }
+            ast.Inspect(fn.Body, func(n ast.Node) bool {
+                if ident, ok := n.(*ast.Ident); ok {
+                    if name, ok := params[ident.Name]; ok {
+                        read[ident.Name] = name
+                    }
+                }
+                return true
+            })
+        }
+    }
+    if len(read) < 10 {
+        t.Fatalf("found only %d params read by collectFilters: %v", len(read), read)
+    }
+
+    query := url.Values{"strict": {"true"}}
+    for ident, name := range read {
+        if _, ok := knownQueryParams[name]; !ok {
+            t.Errorf("%s (%q) is read by collectFilters but unknown to strict mode", ident, name)
+        }
+        query.Set(name, "")
+    }
+    r := httptest.NewRequest(http.MethodGet, "/api/predictions?"+query.Encode(), nil)
+    if unknown := unknownQueryParams(r); len(unknown) > 0 {
+        t.Errorf("strict mode rejects params collectFilters reads: %v", unknown)
+    }
+}
+
+func TestStrictRejectsUnknownParams(t *testing.T) {
+    tests := []struct {
+        query string
+        want  []string
+    }{
+        {"surfce=Clay", nil},
+        {"strict=false&surfce=Clay", nil},
+        {"strict=true&surface=Clay", nil},
+        {"strict=true&surfce=Clay", []string{"surfce"}},
+        {"strict=true&surfce=Clay&minConfidance=60&page=2", []string{"minConfidance", "surfce"}},
+    }
+    for _, tt := range tests {
+        r := httptest.NewRequest(http.MethodGet, "/api/predictions?"+tt.query, nil)
+        got := unknownQueryParams(r)
+        if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+            t.Errorf("%s: unknown = %v, want %v", tt.query, got, tt.want)
+        }
+    }
+
+    r := httptest.NewRequest(http.MethodGet, "/api/predictions?strict=true&surfce=Clay", nil)
+    _, err := collectFilters(r)
+    if err == nil || !strings.Contains(err.Error(), "unknown query params: surfce") {
+        t.Errorf("collectFilters error = %v, want the unknown param listed", err)
+    }
+}
@@ -4,6 +4,7 @@
 -- Enable necessary extensions
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 CREATE EXTENSION IF NOT EXISTS "pg_stat_statements";
+CREATE EXTENSION IF NOT EXISTS "unaccent";
 
 -- System metadata table (single row with system-wide information)
 CREATE TABLE system_metadata (
@@ -343,6 +344,15 @@ CREATE INDEX idx_live_matches_identifier ON live_matches(match_identifier);
 CREATE INDEX idx_live_matches_status ON live_matches(live_status);
 CREATE INDEX idx_live_matches_updated ON live_matches(last_updated);
 
+-- Opening odds per match, used by the dashboard to show line movement
+-- (current odds live on predictions.odds_player1/odds_player2)
+CREATE TABLE odds_history (
+    match_id VARCHAR(255) PRIMARY KEY,  -- Matches predictions.match_id format
+    opening_odds_player1 NUMERIC(8,2),
+    opening_odds_player2 NUMERIC(8,2),
+    recorded_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
 -- Function to update live match status
 CREATE OR REPLACE FUNCTION update_live_match(
     p_match_identifier VARCHAR(255),